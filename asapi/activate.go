@@ -0,0 +1,121 @@
+package asapi
+
+import (
+	"time"
+)
+
+// ActivationStep 激活流程的步骤
+type ActivationStep string
+
+// 激活流程的步骤，按执行顺序排列
+const (
+	ActivationStepVersion    ActivationStep = "version"    // GetUserVersion 检查激活和清理认证状态
+	ActivationStepProfile    ActivationStep = "profile"    // UserActivate 获取激活信息
+	ActivationStepConfirm    ActivationStep = "confirm"    // 确认用户信息
+	ActivationStepAuthStatus ActivationStep = "authstatus" // UpdateAuthStatus 更新认证状态
+)
+
+// ActivationStepResult 激活流程中单个步骤的结果
+type ActivationStepResult struct {
+	Step     ActivationStep
+	Skipped  bool          // 未执行
+	Result   *ErrorResult  // 错误结果，成功或未执行时为nil
+	Duration time.Duration // 执行时间
+}
+
+// ActivationResult 激活流程的结果
+type ActivationResult struct {
+	UID       string
+	Version   *GetUserVersionResult // 激活前的用户版本信息
+	Profile   *UserActivateResult   // 激活信息，未获取时为nil
+	Activated bool                  // 本次完成了激活
+	Steps     []*ActivationStepResult
+}
+
+// Step 返回指定步骤的结果，没有执行到该步骤时返回nil
+func (r *ActivationResult) Step(step ActivationStep) *ActivationStepResult {
+	for _, s := range r.Steps {
+		if s.Step == step {
+			return s
+		}
+	}
+	return nil
+}
+
+// NeedActivate 检查用户是否需要激活（未激活或需要清理认证信息）
+func (v *GetUserVersionResult) NeedActivate() bool {
+	return v.Activate == 1 || v.ClearAuth == 1
+}
+
+// ActivationOptions 激活流程参数
+type ActivationOptions struct {
+	// Confirm 确认激活信息，返回错误时终止激活；
+	// 为nil时校验激活信息的必填字段以及手机号、身份证号、性别和用户类型
+	Confirm func(profile *UserActivateResult) error
+	// Force 用户已激活且不需要清理认证信息时仍然执行激活
+	Force bool
+}
+
+// ActivateAccount 执行账号激活流程：
+// 检查用户版本中的激活和清理认证状态，获取激活信息，确认用户信息，更新认证状态；
+// 用户不需要激活时跳过后续步骤。res记录每个步骤的结果，result为第一个失败步骤的错误结果
+func (ah *AuthorizeHandle) ActivateAccount(uid string, opts *ActivationOptions) (res *ActivationResult, result *ErrorResult) {
+	if opts == nil {
+		opts = new(ActivationOptions)
+	}
+	confirm := opts.Confirm
+	if confirm == nil {
+		confirm = func(profile *UserActivateResult) error {
+			return profile.Validate()
+		}
+	}
+
+	res = &ActivationResult{UID: uid}
+	run := func(step ActivationStep, fn func() *ErrorResult) bool {
+		sr := &ActivationStepResult{Step: step}
+		res.Steps = append(res.Steps, sr)
+		if result != nil {
+			sr.Skipped = true
+			return false
+		}
+		start := time.Now()
+		sr.Result = fn()
+		sr.Duration = time.Since(start)
+		result = sr.Result
+		return result == nil
+	}
+	skip := func(steps ...ActivationStep) {
+		for _, step := range steps {
+			res.Steps = append(res.Steps, &ActivationStepResult{Step: step, Skipped: true})
+		}
+	}
+
+	if !run(ActivationStepVersion, func() (r *ErrorResult) {
+		res.Version, r = ah.GetUserVersion(uid)
+		return
+	}) {
+		skip(ActivationStepProfile, ActivationStepConfirm, ActivationStepAuthStatus)
+		return
+	}
+	if !res.Version.NeedActivate() && !opts.Force {
+		skip(ActivationStepProfile, ActivationStepConfirm, ActivationStepAuthStatus)
+		return
+	}
+
+	run(ActivationStepProfile, func() (r *ErrorResult) {
+		res.Profile, r = ah.UserActivate(uid)
+		return
+	})
+	run(ActivationStepConfirm, func() *ErrorResult {
+		if err := confirm(res.Profile); err != nil {
+			return NewErrorResult(err.Error())
+		}
+		return nil
+	})
+	run(ActivationStepAuthStatus, func() *ErrorResult {
+		return ah.UpdateAuthStatus(uid)
+	})
+	res.Activated = result == nil
+
+	return
+}
@@ -0,0 +1,73 @@
+package asapi_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/asapi/fakeas"
+)
+
+func TestActivateAccount(t *testing.T) {
+	var (
+		version = asapi.GetUserVersionResult{Activate: 1}
+		profile = asapi.UserActivateResult{University: "A", UserCode: "001", MobilePhone: "13800000000", Sex: asapi.SexMale, UserType: asapi.UserTypeStudent}
+		updated int
+	)
+	s := &fakeas.Server{}
+	s.GetUserVersion = func(map[string]interface{}) (*asapi.GetUserVersionResult, *asapi.ErrorResult) {
+		v := version
+		return &v, nil
+	}
+	s.UserActivate = func(map[string]interface{}) (*asapi.UserActivateResult, *asapi.ErrorResult) {
+		p := profile
+		return &p, nil
+	}
+	s.UpdateAuthStatus = func(map[string]interface{}) *asapi.ErrorResult {
+		updated++
+		return nil
+	}
+	ah := newFakeHandle(t, s, nil)
+
+	res, result := ah.ActivateAccount("u1", nil)
+	if result != nil {
+		t.Fatal(result)
+	}
+	if !res.Activated || res.Profile.UserCode != "001" || updated != 1 || len(res.Steps) != 4 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	// 确认失败时不更新认证状态
+	res, result = ah.ActivateAccount("u1", &asapi.ActivationOptions{Confirm: func(*asapi.UserActivateResult) error {
+		return errors.New("信息不一致")
+	}})
+	if result == nil || res.Activated || updated != 1 {
+		t.Fatalf("unexpected result: %+v, %v", res, result)
+	}
+	if s := res.Step(asapi.ActivationStepConfirm); s.Result == nil || s.Result.Message != "信息不一致" {
+		t.Fatalf("unexpected confirm step: %+v", s)
+	}
+	if s := res.Step(asapi.ActivationStepAuthStatus); !s.Skipped {
+		t.Fatalf("expected auth status step to be skipped: %+v", s)
+	}
+
+	// 默认校验激活信息
+	profile.IDCard = "110105194912310021"
+	if _, result = ah.ActivateAccount("u1", nil); result == nil || updated != 1 {
+		t.Fatalf("expected validation error, got %v", result)
+	}
+
+	// 已激活时跳过
+	version.Activate = 0
+	res, result = ah.ActivateAccount("u1", nil)
+	if result != nil || res.Activated || res.Profile != nil || !res.Step(asapi.ActivationStepProfile).Skipped {
+		t.Fatalf("unexpected result: %+v, %v", res, result)
+	}
+
+	// 需要清理认证信息时重新激活
+	version.ClearAuth = 1
+	profile.IDCard = ""
+	if res, result = ah.ActivateAccount("u1", nil); result != nil || !res.Activated || updated != 2 {
+		t.Fatalf("unexpected result: %+v, %v", res, result)
+	}
+}
@@ -1,6 +1,9 @@
 package asapi
 
+//go:generate go run ../cmd/asgen -spec api.yaml -o zz_generated.go -fake fakeas/zz_generated.go
+
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -11,11 +14,15 @@ var (
 )
 
 // InitAPI API初始化
+//
+// Deprecated: 使用 NewAuthorizeHandle 或 sdk.Client 的 Authorize 方法
 func InitAPI(cfg *Config) {
 	gAuthorize = NewAuthorizeHandle(cfg)
 }
 
 // GetAuthorize 获取全局的授权处理
+//
+// Deprecated: 使用 NewAuthorizeHandle 或 sdk.Client 的 Authorize 方法
 func GetAuthorize() *AuthorizeHandle {
 	return gAuthorize
 }
@@ -119,12 +126,6 @@ func GetUpgradeToken(password, uid, clientID, clientSecret string) (info map[str
 	return
 }
 
-// MergeUser 合并认证用户
-func MergeUser(req *AuthorizeMergeUserRequest) (result *ErrorResult) {
-	result = gAuthorize.MergeUser(req)
-	return
-}
-
 // GetStaffParam 获取学工请求参数
 func GetStaffParam(identify, uid string) (buID, addr string, result *ErrorResult) {
 	buID, addr, result = gAuthorize.GetStaffParam(identify, uid)
@@ -153,18 +154,6 @@ func UserRefreshToken(rtoken string) (tokenInfo *UserTokenInfo, result *ErrorRes
 	return
 }
 
-// MergeTELUser 合并手机号用户
-func MergeTELUser(req *AuthorizeMergeTELUserRequest) (result *ErrorResult) {
-	result = gAuthorize.MergeTELUser(req)
-	return
-}
-
-// ClearAuth 清理用户认证信息
-func ClearAuth(req *ClearAuthRequest) (result *ErrorResult) {
-	result = gAuthorize.ClearAuth(req)
-	return
-}
-
 // GetUserCode 根据用户ID获取UserCode
 func GetUserCode(uid string) (userCode string, result *ErrorResult) {
 	userCode, result = gAuthorize.GetUserCode(uid)
@@ -177,12 +166,6 @@ func AddStaffUser(req *AddStaffUserRequest) (result *ErrorResult) {
 	return
 }
 
-// UpdateUserBasic 更新用户基础信息
-func UpdateUserBasic(req *UpdateUserBasicRequest) (result *ErrorResult) {
-	result = gAuthorize.UpdateUserBasic(req)
-	return
-}
-
 // GetUserVersion 获取用户版本信息
 func GetUserVersion(uid string) (resResult *GetUserVersionResult, result *ErrorResult) {
 	resResult, result = gAuthorize.GetUserVersion(uid)
@@ -224,3 +207,43 @@ func GetAntUIDByUniversity(userID, university string) (uid string, result *Error
 	uid, result = gAuthorize.GetAntUIDByUniversity(userID, university)
 	return
 }
+
+// BatchAddStaffUser 批量增加学工用户
+func BatchAddStaffUser(ctx context.Context, reqs []*AddStaffUserRequest, opts *BatchOptions) *BatchReport {
+	return gAuthorize.BatchAddStaffUser(ctx, reqs, opts)
+}
+
+// StreamAddStaffUser 从通道中读取并批量增加学工用户
+func StreamAddStaffUser(ctx context.Context, reqs <-chan *AddStaffUserRequest, opts *BatchOptions, fn func(*BatchResult)) *BatchReport {
+	return gAuthorize.StreamAddStaffUser(ctx, reqs, opts, fn)
+}
+
+// PreviewMergeUser 生成合并用户的预览
+func PreviewMergeUser(req *AuthorizeMergeUserRequest) (*MergePlan, *ErrorResult) {
+	return gAuthorize.PreviewMergeUser(req)
+}
+
+// PreviewMergeTELUser 生成合并手机号用户的预览
+func PreviewMergeTELUser(req *AuthorizeMergeTELUserRequest) (*MergePlan, *ErrorResult) {
+	return gAuthorize.PreviewMergeTELUser(req)
+}
+
+// ExecuteMerge 按预览执行合并并保存审计记录
+func ExecuteMerge(plan *MergePlan, auditor MergeAuditor) (*MergeAudit, *ErrorResult) {
+	return gAuthorize.ExecuteMerge(plan, auditor)
+}
+
+// RollbackMerge 根据审计记录恢复合并前的用户信息
+func RollbackMerge(audit *MergeAudit, auditor MergeAuditor) *ErrorResult {
+	return gAuthorize.RollbackMerge(audit, auditor)
+}
+
+// ActivateAccount 执行账号激活流程
+func ActivateAccount(uid string, opts *ActivationOptions) (*ActivationResult, *ErrorResult) {
+	return gAuthorize.ActivateAccount(uid, opts)
+}
+
+// NewSyncer 创建用户同步
+func NewSyncer(opts *SyncOptions) *Syncer {
+	return gAuthorize.NewSyncer(opts)
+}
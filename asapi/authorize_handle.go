@@ -1,40 +1,95 @@
 package asapi
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/antlinker/go-cache"
+	"github.com/antlinker/sdk/logging"
+	"github.com/antlinker/sdk/metrics"
+	"github.com/antlinker/sdk/redact"
+	"github.com/antlinker/sdk/tracing"
+	"github.com/antlinker/sdk/utils"
+	"github.com/antlinker/sdk/validation"
 	"github.com/astaxie/beego/httplib"
 )
 
 // NewAuthorizeHandle 创建授权处理
 func NewAuthorizeHandle(cfg *Config) *AuthorizeHandle {
-	ah := &AuthorizeHandle{
-		cfg: cfg,
-		th:  NewTokenHandle(cfg),
-	}
-
-	if ah.cfg.IsEnabledCache {
-		if ah.cfg.CacheGCInterval == 0 {
-			ah.cfg.CacheGCInterval = 300
+	var (
+		c, rc *cache.Cache
+		j     *janitor
+	)
+	if cfg.IsEnabledCache {
+		if cfg.CacheGCInterval == 0 {
+			cfg.CacheGCInterval = 300
 		}
-		ah.cache = cache.New(0, time.Second*time.Duration(ah.cfg.CacheGCInterval))
+		c = cache.New(0, 0)
 		// 加入两个接口缓存
-		ah.routerCache = cache.New(0, time.Second*time.Duration(ah.cfg.CacheGCInterval))
+		rc = cache.New(0, 0)
+		j = runJanitor(time.Second*time.Duration(cfg.CacheGCInterval), c, rc)
+	}
+	ah := newAuthorizeHandle(cfg, NewTokenHandle(cfg), c, rc)
+	ah.tokenHandle().endpoints.startHealthCheck(time.Duration(cfg.HealthCheckInterval)*time.Second, ah.tokenHandle().roundTripper)
+	ah.release = func() {
+		ah.tokenHandle().endpoints.stopHealthCheck()
+		if j != nil {
+			j.Stop()
+			c.Flush()
+			rc.Flush()
+		}
+		closeIdleConnections(ah.tokenHandle().roundTripper())
 	}
-
 	return ah
 }
 
+// newAuthorizeHandle 使用已有的令牌处理和缓存创建授权处理
+func newAuthorizeHandle(cfg *Config, th *TokenHandle, c, rc *cache.Cache) *AuthorizeHandle {
+	if cfg.IsEnabledCache && cfg.CacheGCInterval == 0 {
+		cfg.CacheGCInterval = 300
+	}
+	return &AuthorizeHandle{
+		cfg:         cfg,
+		tokens:      &tokenRef{th: th},
+		cache:       c,
+		routerCache: rc,
+		inflight:    new(utils.InFlight),
+		closeOnce:   new(sync.Once),
+		limiter:     newLimiter(cfg.RateLimit),
+	}
+}
+
 // AuthorizeHandle 授权处理
 type AuthorizeHandle struct {
 	cfg         *Config
-	th          *TokenHandle
+	tokens      *tokenRef
 	cache       *cache.Cache
 	routerCache *cache.Cache
+	inflight    *utils.InFlight
+	closeOnce   *sync.Once
+	release     func()          // 关闭时释放由本处理创建的资源
+	ctx         context.Context // 由WithContext设置，用于链路追踪和取消请求
+	log         logging.Logger
+	limiter     *limiter
+}
+
+// tokenHandle 获取当前使用的令牌处理
+func (ah *AuthorizeHandle) tokenHandle() *TokenHandle {
+	return ah.tokens.get()
+}
+
+// routerCacheKey 接口缓存的键，加上服务标识以免注册表中共享缓存的服务互相读取
+func (ah *AuthorizeHandle) routerCacheKey(r RequestReader) string {
+	hash := r.Hash()
+	if hash == "" {
+		return ""
+	}
+	return ah.cfg.ServiceIdentify + ":" + hash
 }
 
 // getFromRouterCache 从路由的缓存中读数据
@@ -46,7 +101,7 @@ func (ah *AuthorizeHandle) getFromRouterCache(router string, r RequestReader) (b
 		ok = false
 		return
 	}
-	key := r.Hash()
+	key := ah.routerCacheKey(r)
 	if key == "" {
 		return
 	}
@@ -68,7 +123,7 @@ func (ah *AuthorizeHandle) setRouterCache(router string, r RequestReader, v inte
 	if expires <= 0 {
 		return
 	}
-	key := r.Hash()
+	key := ah.routerCacheKey(r)
 	if key == "" {
 		return
 	}
@@ -76,9 +131,68 @@ func (ah *AuthorizeHandle) setRouterCache(router string, r RequestReader, v inte
 	ah.routerCache.Set(key, b, time.Duration(expires)*time.Second)
 }
 
-// 请求数据
-func (ah *AuthorizeHandle) request(router, method string, reqHandle func(req *httplib.BeegoHTTPRequest) (*httplib.BeegoHTTPRequest, *ErrorResult), v interface{}) (result *ErrorResult) {
-	req := httplib.NewBeegoRequest(ah.cfg.GetURL(router), method)
+// 请求数据，logArgs为附加的日志字段
+// 配置了多个授权服务节点时按负载均衡策略选择节点，节点不可用时切换到下一个节点
+func (ah *AuthorizeHandle) request(router, method string, reqHandle func(req *httplib.BeegoHTTPRequest) (*httplib.BeegoHTTPRequest, *ErrorResult), v interface{}, logArgs ...interface{}) (result *ErrorResult) {
+	eps := ah.tokenHandle().endpoints.order()
+	for i, ep := range eps {
+		var nerr *nodeError
+		result, nerr = ah.requestNode(ep, router, method, reqHandle, v, logArgs...)
+		if i == len(eps)-1 || !canFailover(method, nerr) {
+			return
+		}
+	}
+	return
+}
+
+// requestNode 向指定的授权服务节点请求数据，并记录节点是否可用
+func (ah *AuthorizeHandle) requestNode(ep *endpoint, router, method string, reqHandle func(req *httplib.BeegoHTTPRequest) (*httplib.BeegoHTTPRequest, *ErrorResult), v interface{}, logArgs ...interface{}) (result *ErrorResult, nerr *nodeError) {
+	start := time.Now()
+	result, nerr = ah.requestURL(ep.url, router, method, reqHandle, v, logArgs...)
+	if nerr != nil && (errors.Is(nerr.err, context.Canceled) || ah.context().Err() != nil) {
+		// 请求被取消（如对冲请求中较慢的一个），不能说明节点不可用
+		return
+	}
+	if result == nil || nerr != nil {
+		ah.tokenHandle().endpoints.observe(ep, time.Since(start), nerr != nil)
+	}
+	return
+}
+
+// requestURL 向baseURL指定的授权服务请求数据，nerr不为nil表示节点不可用
+func (ah *AuthorizeHandle) requestURL(baseURL, router, method string, reqHandle func(req *httplib.BeegoHTTPRequest) (*httplib.BeegoHTTPRequest, *ErrorResult), v interface{}, logArgs ...interface{}) (result *ErrorResult, nerr *nodeError) {
+	if err := ah.inflight.Acquire(); err != nil {
+		result = NewErrorResult(err.Error())
+		return
+	}
+	defer ah.inflight.Release()
+
+	url := joinURL(baseURL, router)
+	req := httplib.NewBeegoRequest(url, method)
+	rt := ah.tokenHandle().roundTripper()
+	if ah.ctx != nil {
+		// 由WithContext绑定的ctx取消时中断请求
+		rt = &contextTransport{ctx: ah.ctx, next: rt}
+	}
+	req.SetTransport(rt)
+
+	_, span := tracing.StartHTTP(ah.context(), "asapi "+router, method, url, req.GetRequest().Header)
+	var status int
+	start := time.Now()
+	defer func() {
+		endSpan(span, status, result)
+		metrics.ObserveRequest(metrics.ComponentASAPI, router, resultCode(result), start)
+		ah.logRequest(router, method, status, start, result, logArgs)
+	}()
+
+	if err := ah.limiter.wait(ah.context(), router); err != nil {
+		if err == ErrRateLimited {
+			result = NewErrorResult(err.Error(), http.StatusTooManyRequests)
+		} else {
+			result = NewErrorResult(err.Error())
+		}
+		return
+	}
 
 	if reqHandle != nil {
 		vreq, vresult := reqHandle(req)
@@ -92,8 +206,16 @@ func (ah *AuthorizeHandle) request(router, method string, reqHandle func(req *ht
 	res, err := req.Response()
 	if err != nil {
 		result = NewErrorResult(err.Error())
+		nerr = &nodeError{err: err}
 		return
 	}
+	status = res.StatusCode
+	if unavailable(status) {
+		nerr = &nodeError{status: status}
+	}
+	if status == http.StatusTooManyRequests {
+		ah.limiter.retryAfter(res.Header.Get("Retry-After"))
+	}
 
 	buf, err := req.Bytes()
 	if err != nil {
@@ -112,7 +234,7 @@ func (ah *AuthorizeHandle) request(router, method string, reqHandle func(req *ht
 			return
 		} // 设置缓存
 	default:
-		result = NewErrorResult(string(buf), res.StatusCode)
+		result = NewErrorResult(redact.String(string(buf)), res.StatusCode)
 	}
 
 	return
@@ -140,7 +262,7 @@ func (ah *AuthorizeHandle) tokenPost(router string, body, v interface{}) (result
 	}
 
 	reqHandle := func(req *httplib.BeegoHTTPRequest) (*httplib.BeegoHTTPRequest, *ErrorResult) {
-		token, result := ah.th.Get()
+		token, result := ah.tokenHandle().get(ah.context())
 		if result != nil {
 			return req, result
 		}
@@ -156,7 +278,11 @@ func (ah *AuthorizeHandle) tokenPost(router string, body, v interface{}) (result
 		}
 		return req, nil
 	}
-	result = ah.request(router, http.MethodPost, reqHandle, v)
+	var logArgs []interface{}
+	if uid := uidOf(body); uid != "" {
+		logArgs = append(logArgs, logging.KeyUID, logging.MaskUID(uid))
+	}
+	result = ah.request(router, http.MethodPost, reqHandle, v, logArgs...)
 	if result != nil {
 		return
 	}
@@ -172,48 +298,49 @@ func (ah *AuthorizeHandle) GetConfig() (cfg *Config) {
 	return
 }
 
+// SetCredentials 更新客户端ID和秘钥（用于配置热更新），已获取的访问令牌将被丢弃；
+// 注册表中的授权处理切换到新凭据对应的令牌处理，不影响共享令牌的其他服务
+func (ah *AuthorizeHandle) SetCredentials(clientID, clientSecret string) {
+	ah.tokens.setCredentials(clientID, clientSecret)
+}
+
+// Credentials 获取当前使用的客户端ID和秘钥
+func (ah *AuthorizeHandle) Credentials() (clientID, clientSecret string) {
+	return ah.tokenHandle().credentials()
+}
+
+// SetTransport 设置请求授权服务使用的RoundTripper（如录制和回放请求），获取令牌的请求同样使用
+func (ah *AuthorizeHandle) SetTransport(rt http.RoundTripper) {
+	ah.tokenHandle().SetTransport(rt)
+}
+
 // LoginUserInfo 登录用户信息
 type LoginUserInfo struct {
-	MobilePhone     string // 手机号码
-	UserCode        string // 用户代码
-	IDCard          string // 身份证号码
-	Password        string // 登录密码
-	DefaultPassword string // 默认登录密码
-	University      string // 学校ID
-	UserType        string // 用户类型
+	MobilePhone     string   // 手机号码
+	UserCode        string   // 用户代码
+	IDCard          string   // 身份证号码
+	Password        string   // 登录密码
+	DefaultPassword string   // 默认登录密码
+	University      string   // 学校ID
+	UserType        UserType // 用户类型
 }
 
 // VerifyLogin 验证登录
 // username 用户ID（唯一标识）
 // password 密码
 func (ah *AuthorizeHandle) VerifyLogin(username, password string) (info *LoginUserInfo, result *ErrorResult) {
-	body := map[string]interface{}{
-		"ServiceIdentify": ah.cfg.ServiceIdentify,
-		"UID":             username,
-		"Password":        password,
-	}
-	var loginInfo LoginUserInfo
-	result = ah.tokenPost("/api/authorize/verifylogin", body, &loginInfo)
-	if result != nil {
-		return
+	body := &passwordRequest{
+		UID:      username,
+		Password: password,
 	}
-	info = &loginInfo
+	info, result = call[LoginUserInfo](ah, "/api/authorize/verifylogin", body)
 	return
 }
 
 // GetUser 验证登录
 // uid 用户ID（唯一标识）
 func (ah *AuthorizeHandle) GetUser(uid string) (info *LoginUserInfo, result *ErrorResult) {
-	body := map[string]interface{}{
-		"ServiceIdentify": ah.cfg.ServiceIdentify,
-		"UID":             uid,
-	}
-	var loginInfo LoginUserInfo
-	result = ah.tokenPost("/api/authorize/getuser", body, &loginInfo)
-	if result != nil {
-		return
-	}
-	info = &loginInfo
+	info, result = call[LoginUserInfo](ah, "/api/authorize/getuser", &uidRequest{UID: uid})
 	return
 }
 
@@ -230,23 +357,24 @@ type AuthorizeAddUserRequest struct {
 
 // AddUser 增加用户
 func (ah *AuthorizeHandle) AddUser(uid string, user *AuthorizeAddUserRequest) (result *ErrorResult) {
-	identify := ah.cfg.ServiceIdentify
+	return ah.addUser(uid, user, true)
+}
 
-	if v := user.ServiceIdentify; v != "" {
-		identify = v
+// addUser 增加用户，checkPolicy为false时不检查密码强度（用于回滚合并时恢复已有的用户）
+func (ah *AuthorizeHandle) addUser(uid string, user *AuthorizeAddUserRequest, checkPolicy bool) (result *ErrorResult) {
+	if result = ah.validate(user, uid); result != nil {
+		return
 	}
-
-	body := map[string]interface{}{
-		"ServiceIdentify": identify,
-		"UID":             uid,
-		"MobilePhone":     user.MobilePhone,
-		"UserCode":        user.UserCode,
-		"IDCard":          user.IDCard,
-		"Password":        user.Password,
-		"DefaultPassword": user.DefaultPassword,
-		"University":      user.University,
+	if checkPolicy {
+		if result = ah.checkPassword(user.Password, &validation.PasswordUser{UID: uid, UserCode: user.UserCode, IDCard: user.IDCard, MobilePhone: user.MobilePhone}); result != nil {
+			return
+		}
 	}
-	result = ah.tokenPost("/api/authorize/adduser", body, nil)
+	body := &struct {
+		UID string
+		*AuthorizeAddUserRequest
+	}{uid, user}
+	_, result = call[struct{}](ah, "/api/authorize/adduser", body)
 	return
 }
 
@@ -261,59 +389,56 @@ type AuthorizeEditUserRequest struct {
 
 // EditUser 编辑用户信息
 func (ah *AuthorizeHandle) EditUser(uid string, user *AuthorizeEditUserRequest) (result *ErrorResult) {
-	identify := ah.cfg.ServiceIdentify
-
-	if v := user.ServiceIdentify; v != "" {
-		identify = v
-	}
-	body := map[string]interface{}{
-		"ServiceIdentify": identify,
-		"UID":             uid,
-		"MobilePhone":     user.MobilePhone,
-		"UserCode":        user.UserCode,
-		"IDCard":          user.IDCard,
-		"University":      user.University,
+	if result = ah.validate(user, uid); result != nil {
+		return
 	}
-	result = ah.tokenPost("/api/authorize/edituser", body, nil)
+	body := &struct {
+		UID string
+		*AuthorizeEditUserRequest
+	}{uid, user}
+	_, result = call[struct{}](ah, "/api/authorize/edituser", body)
 	return
 }
 
 // DelUser 删除用户
 func (ah *AuthorizeHandle) DelUser(uid string) (result *ErrorResult) {
-	body := map[string]interface{}{
-		"ServiceIdentify": ah.cfg.ServiceIdentify,
-		"UID":             uid,
-	}
-	result = ah.tokenPost("/api/authorize/deluser", body, nil)
+	_, result = call[struct{}](ah, "/api/authorize/deluser", &uidRequest{UID: uid})
 	return
 }
 
 // ModifyPwd 修改密码
 func (ah *AuthorizeHandle) ModifyPwd(uid, password string, services ...string) (result *ErrorResult) {
-	identify := ah.cfg.ServiceIdentify
+	body := &passwordRequest{
+		UID:      uid,
+		Password: password,
+	}
 	if len(services) > 0 {
-		identify = services[0]
+		body.ServiceIdentify = services[0]
 	}
-	body := map[string]interface{}{
-		"ServiceIdentify": identify,
-		"UID":             uid,
-		"Password":        password,
+	if ah.cfg.PasswordPolicy != nil {
+		// 先进行不需要用户信息的检查，检查失败时不再请求授权服务
+		if result = ah.checkPassword(password, nil); result != nil {
+			return
+		}
+		info, iresult := call[LoginUserInfo](ah, "/api/authorize/getuser", &uidRequest{ServiceIdentify: body.ServiceIdentify, UID: uid})
+		if iresult != nil {
+			result = iresult
+			return
+		}
+		user := &validation.PasswordUser{UID: uid, UserCode: info.UserCode, IDCard: info.IDCard, MobilePhone: info.MobilePhone}
+		if result = ah.checkPassword(password, user); result != nil {
+			return
+		}
 	}
-	result = ah.tokenPost("/api/authorize/modifypwd", body, nil)
+	_, result = call[struct{}](ah, "/api/authorize/modifypwd", body)
 	return
 }
 
 // CheckDefaultPwd 检查默认密码
 func (ah *AuthorizeHandle) CheckDefaultPwd(uid string) (isDefault bool, result *ErrorResult) {
-	body := map[string]interface{}{
-		"ServiceIdentify": ah.cfg.ServiceIdentify,
-		"UID":             uid,
-	}
-
-	var res struct {
+	res, result := call[struct {
 		IsDefault bool
-	}
-	result = ah.tokenPost("/api/authorize/checkdefaultpwd", body, &res)
+	}](ah, "/api/authorize/checkdefaultpwd", &uidRequest{UID: uid})
 	if result != nil {
 		return
 	}
@@ -323,13 +448,13 @@ func (ah *AuthorizeHandle) CheckDefaultPwd(uid string) (isDefault bool, result *
 
 // GetToken 获取访问令牌
 func (ah *AuthorizeHandle) GetToken() (token string, result *ErrorResult) {
-	token, result = ah.th.Get()
+	token, result = ah.tokenHandle().get(ah.context())
 	return
 }
 
 // ForceGetToken 强制获取访问令牌
 func (ah *AuthorizeHandle) ForceGetToken() (tokenString string, result *ErrorResult) {
-	token, result := ah.th.ForceGet()
+	token, result := ah.tokenHandle().forceGet(ah.context())
 	if result != nil {
 		return
 	}
@@ -337,6 +462,11 @@ func (ah *AuthorizeHandle) ForceGetToken() (tokenString string, result *ErrorRes
 	return
 }
 
+// tokenCacheKey 验证令牌结果的缓存键，多个服务标识共享缓存时按服务标识区分
+func (ah *AuthorizeHandle) tokenCacheKey(token string) string {
+	return ah.cfg.ServiceIdentify + ":" + token
+}
+
 // VerifyToken 验证令牌
 func (ah *AuthorizeHandle) VerifyToken(token string) (userID, clientID string, result *ErrorResult) {
 	const (
@@ -346,7 +476,7 @@ func (ah *AuthorizeHandle) VerifyToken(token string) (userID, clientID string, r
 
 	if ah.cfg.IsEnabledCache {
 		// 检查缓存数据
-		if at, ok := ah.cache.Get(token); ok {
+		if at, ok := ah.cache.Get(ah.tokenCacheKey(token)); ok {
 			if atm, ok := at.(map[string]string); ok {
 				userID = atm[userIDKey]
 				clientID = atm[clientIDKey]
@@ -367,7 +497,7 @@ func (ah *AuthorizeHandle) VerifyToken(token string) (userID, clientID string, r
 		ExpiresIn int    `json:"expires_in"`
 	}
 
-	result = ah.request("/oauth2/verify", http.MethodGet, reqHandle, &resData)
+	result = ah.hedgedGet("/oauth2/verify", reqHandle, &resData)
 	if result != nil {
 		return
 	}
@@ -380,7 +510,7 @@ func (ah *AuthorizeHandle) VerifyToken(token string) (userID, clientID string, r
 			userIDKey:   resData.UserID,
 			clientIDKey: resData.ClientID,
 		}
-		ah.cache.Set(token, data, time.Duration(resData.ExpiresIn-ah.cfg.CacheGCInterval)*time.Second)
+		ah.cache.Set(ah.tokenCacheKey(token), data, time.Duration(resData.ExpiresIn-ah.cfg.CacheGCInterval)*time.Second)
 	}
 
 	return
@@ -401,7 +531,7 @@ type VerifyTokenInfo struct {
 func (ah *AuthorizeHandle) VerifyTokenV2(token string) (*VerifyTokenInfo, *ErrorResult) {
 	if ah.cfg.IsEnabledCache {
 		// 检查缓存数据
-		if at, ok := ah.cache.Get(token); ok {
+		if at, ok := ah.cache.Get(ah.tokenCacheKey(token) + ":v2"); ok {
 			if atm, ok := at.(*VerifyTokenInfo); ok {
 				return atm, nil
 			}
@@ -414,12 +544,11 @@ func (ah *AuthorizeHandle) VerifyTokenV2(token string) (*VerifyTokenInfo, *Error
 	}
 
 	var resData VerifyTokenInfo
-	if result := ah.request("/oauth2/verify/v2",
-		http.MethodGet, reqHandle, &resData); result != nil {
+	if result := ah.hedgedGet("/oauth2/verify/v2", reqHandle, &resData); result != nil {
 		return nil, result
 	}
 	if ah.cfg.IsEnabledCache && ah.cfg.CacheGCInterval < resData.ExpiresIn {
-		ah.cache.Set(token, &resData, time.Duration(resData.ExpiresIn-ah.cfg.CacheGCInterval)*time.Second)
+		ah.cache.Set(ah.tokenCacheKey(token)+":v2", &resData, time.Duration(resData.ExpiresIn-ah.cfg.CacheGCInterval)*time.Second)
 	}
 	return &resData, nil
 }
@@ -431,11 +560,12 @@ func (ah *AuthorizeHandle) GetUpgradeToken(password, uid, clientID, clientSecret
 		req = req.SetBasicAuth(clientID, clientSecret)
 
 		req = req.Param("grant_type", "password")
+		selfID, selfSecret := ah.tokenHandle().credentials()
 		userInfo := map[string]interface{}{
-			"LoginModel":   9,
+			"LoginModel":   LoginModelUpgrade,
 			"UserName":     uid,
-			"ClientID":     ah.GetConfig().ClientID,
-			"ClientSecret": ah.GetConfig().ClientSecret,
+			"ClientID":     selfID,
+			"ClientSecret": selfSecret,
 		}
 
 		buf, _ := json.Marshal(userInfo)
@@ -463,10 +593,11 @@ type UserTokenInfo struct {
 
 // UserLoginToken 用户登录令牌
 func (ah *AuthorizeHandle) UserLoginToken(userName, password, service string) (*UserTokenInfo, *ErrorResult) {
+	clientID, clientSecret := ah.tokenHandle().credentials()
 	return ah.GetAccessTokenByPassword(PasswordRequest{
-		ClientID:     ah.GetConfig().ClientID,
-		ClientSecret: ah.GetConfig().ClientSecret,
-		LoginModel:   1,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		LoginModel:   LoginModelPhone,
 		UserName:     userName,
 		Service:      service,
 		Password:     password,
@@ -475,17 +606,21 @@ func (ah *AuthorizeHandle) UserLoginToken(userName, password, service string) (*
 
 // PasswordRequest 密码模式请求参数
 type PasswordRequest struct {
-	ClientID     string // 客户端ID
-	ClientSecret string // 客户端秘钥
-	LoginModel   int    // 登录模式（1手机号、身份证号登录,2学校、学号登录）
-	University   string // 学校编号
-	UserName     string // 用户名
-	Service      string // 服务标识
-	Password     string // 密码
+	ClientID     string     // 客户端ID
+	ClientSecret string     // 客户端秘钥
+	LoginModel   LoginModel // 登录模式（1手机号、身份证号登录,2学校、学号登录）
+	University   string     // 学校编号
+	UserName     string     // 用户名
+	Service      string     // 服务标识
+	Password     string     // 密码
 }
 
 // GetAccessTokenByPassword 使用密码模式获取访问令牌
 func (ah *AuthorizeHandle) GetAccessTokenByPassword(params PasswordRequest) (*UserTokenInfo, *ErrorResult) {
+	if err := params.LoginModel.Validate(); err != nil {
+		return nil, NewErrorResult(err.Error())
+	}
+
 	reqHandle := func(req *httplib.BeegoHTTPRequest) (*httplib.BeegoHTTPRequest, *ErrorResult) {
 		req = req.SetBasicAuth(params.ClientID, params.ClientSecret)
 		req = req.Param("grant_type", "password")
@@ -521,7 +656,7 @@ func (ah *AuthorizeHandle) GetAccessTokenByPassword(params PasswordRequest) (*Us
 func (ah *AuthorizeHandle) UserRefreshToken(rtoken string) (tokenInfo *UserTokenInfo, result *ErrorResult) {
 
 	reqHandle := func(req *httplib.BeegoHTTPRequest) (*httplib.BeegoHTTPRequest, *ErrorResult) {
-		req = req.SetBasicAuth(ah.GetConfig().ClientID, ah.GetConfig().ClientSecret)
+		req = req.SetBasicAuth(ah.tokenHandle().credentials())
 		req = req.Param("grant_type", "refresh_token")
 		req = req.Param("refresh_token", rtoken)
 
@@ -546,20 +681,6 @@ type AuthorizeMergeUserRequest struct {
 	TUniversity string
 }
 
-// MergeUser 合并用户
-func (ah *AuthorizeHandle) MergeUser(req *AuthorizeMergeUserRequest) (result *ErrorResult) {
-	body := map[string]interface{}{
-		"ServiceIdentify": ah.cfg.ServiceIdentify,
-		"UID":             req.UID,
-		"TUID":            req.TUID,
-		"TUserCode":       req.TUserCode,
-		"TUniversity":     req.TUniversity,
-	}
-
-	result = ah.tokenPost("/api/authorize/mergeuser", body, nil)
-	return
-}
-
 // GetStaffParam 获取学工请求参数
 func (ah *AuthorizeHandle) GetStaffParam(identify, uid string) (buID, addr string, result *ErrorResult) {
 	body := &GetStaffParamRequest{
@@ -567,12 +688,10 @@ func (ah *AuthorizeHandle) GetStaffParam(identify, uid string) (buID, addr strin
 		UID:             uid,
 	}
 
-	var resData struct {
+	resData, result := call[struct {
 		BuID string
 		Addr string
-	}
-
-	result = ah.tokenPost("/api/authorize/getstaffparam", body, &resData)
+	}](ah, "/api/authorize/getstaffparam", body)
 	if result != nil {
 		return
 	}
@@ -598,12 +717,7 @@ func (ah *AuthorizeHandle) GetAntStaffParam(uid string) (*GetAntStaffParamResult
 		UID:             uid,
 	}
 
-	var resData GetAntStaffParamResult
-	result := ah.tokenPost("/api/authorize/getstaffparam", body, &resData)
-	if result != nil {
-		return nil, result
-	}
-	return &resData, nil
+	return call[GetAntStaffParamResult](ah, "/api/authorize/getstaffparam", body)
 }
 
 // AuthorizeMergeTELUserRequest 合并手机号用户请求参数
@@ -612,47 +726,15 @@ type AuthorizeMergeTELUserRequest struct {
 	CUID string
 }
 
-// MergeTELUser 合并手机号用户
-func (ah *AuthorizeHandle) MergeTELUser(req *AuthorizeMergeTELUserRequest) (result *ErrorResult) {
-	body := map[string]interface{}{
-		"ServiceIdentify": ah.cfg.ServiceIdentify,
-		"MUID":            req.MUID,
-		"CUID":            req.CUID,
-	}
-
-	result = ah.tokenPost("/api/authorize/mergeteluser", body, nil)
-	return
-}
-
-// ClearAuthRequest 清理用户认证信息请求参数
-type ClearAuthRequest struct {
-	UID        string
-	University string
-}
-
-// ClearAuth 清理用户认证信息
-func (ah *AuthorizeHandle) ClearAuth(req *ClearAuthRequest) (result *ErrorResult) {
-	body := map[string]interface{}{
-		"ServiceIdentify": ah.cfg.ServiceIdentify,
-		"UID":             req.UID,
-		"University":      req.University,
-	}
-
-	result = ah.tokenPost("/api/authorize/clearauth", body, nil)
-	return
-}
-
 // GetUserCode 根据用户ID获取UserCode
 func (ah *AuthorizeHandle) GetUserCode(uid string) (userCode string, result *ErrorResult) {
 	body := &GetUserCodeRequest{
 		UID: uid,
 	}
 
-	var res struct {
+	res, result := call[struct {
 		UserCode string
-	}
-
-	result = ah.tokenPost("/api/authorize/usercode", body, &res)
+	}](ah, "/api/authorize/usercode", body)
 	if result != nil {
 		return
 	}
@@ -669,26 +751,23 @@ type AddStaffUserRequest struct {
 	Password    string // 密码
 	University  string // 学校ID
 	Name        string // 真实姓名
-	Sex         string // 性别（F女,M男）
+	Sex         Sex    // 性别（F女,M男）
 	DeptID      string // 部门或学院ID
 }
 
 // AddStaffUser 增加学工用户
 func (ah *AuthorizeHandle) AddStaffUser(req *AddStaffUserRequest) (result *ErrorResult) {
-	body := map[string]interface{}{
-		"ServiceIdentify": ah.cfg.ServiceIdentify,
-		"UID":             req.UID,
-		"MobilePhone":     req.MobilePhone,
-		"UserCode":        req.UserCode,
-		"IDCard":          req.IDCard,
-		"Password":        req.Password,
-		"University":      req.University,
-		"Name":            req.Name,
-		"Sex":             req.Sex,
-		"DeptID":          req.DeptID,
-	}
-
-	result = ah.tokenPost("/api/authorize/addstaffuser", body, nil)
+	if err := req.Sex.Validate(); err != nil {
+		result = NewErrorResult(err.Error())
+		return
+	}
+	if result = ah.validate(req); result != nil {
+		return
+	}
+	if result = ah.checkPassword(req.Password, &validation.PasswordUser{UID: req.UID, UserCode: req.UserCode, IDCard: req.IDCard, MobilePhone: req.MobilePhone}); result != nil {
+		return
+	}
+	_, result = call[struct{}](ah, "/api/authorize/addstaffuser", req)
 	return
 }
 
@@ -699,19 +778,6 @@ type UpdateUserBasicRequest struct {
 	DeptID string // 部门或学院ID
 }
 
-// UpdateUserBasic 更新用户基础信息
-func (ah *AuthorizeHandle) UpdateUserBasic(req *UpdateUserBasicRequest) (result *ErrorResult) {
-	body := map[string]interface{}{
-		"ServiceIdentify": ah.cfg.ServiceIdentify,
-		"UID":             req.UID,
-		"Name":            req.Name,
-		"DeptID":          req.DeptID,
-	}
-
-	result = ah.tokenPost("/api/authorize/updateuserbasic", body, nil)
-	return
-}
-
 // GetUserVersionResult 用户版本信息
 type GetUserVersionResult struct {
 	ClearAuth int // 清理用户认证信息(0不清理 1清理)
@@ -721,47 +787,25 @@ type GetUserVersionResult struct {
 
 // GetUserVersion 获取用户版本信息
 func (ah *AuthorizeHandle) GetUserVersion(uid string) (resResult *GetUserVersionResult, result *ErrorResult) {
-	body := map[string]interface{}{
-		"ServiceIdentify": ah.cfg.ServiceIdentify,
-		"UID":             uid,
-	}
-
-	var res GetUserVersionResult
-
-	result = ah.tokenPost("/api/authorize/getuserversion", body, &res)
-	if result != nil {
-		return
-	}
-	resResult = &res
+	resResult, result = call[GetUserVersionResult](ah, "/api/authorize/getuserversion", &uidRequest{UID: uid})
 	return
 }
 
 // UserActivateResult 用户激活
 type UserActivateResult struct {
-	MobilePhone string // 手机号码
-	UserCode    string // 用户代码
-	IDCard      string // 身份证号码
-	University  string // 学校ID
-	RealName    string // 真实姓名
-	Sex         string // 性别（F女,M男）
-	DeptID      string // 部门ID(学工是学院或部门，学生是班级)
-	UserType    string // 用户类型（1学生 2老师）
+	MobilePhone string   // 手机号码
+	UserCode    string   // 用户代码
+	IDCard      string   // 身份证号码
+	University  string   // 学校ID
+	RealName    string   // 真实姓名
+	Sex         Sex      // 性别（F女,M男）
+	DeptID      string   // 部门ID(学工是学院或部门，学生是班级)
+	UserType    UserType // 用户类型（1学生 2老师）
 }
 
 // UserActivate 用户激活
 func (ah *AuthorizeHandle) UserActivate(uid string) (resResult *UserActivateResult, result *ErrorResult) {
-	body := map[string]interface{}{
-		"ServiceIdentify": ah.cfg.ServiceIdentify,
-		"UID":             uid,
-	}
-
-	var res UserActivateResult
-
-	result = ah.tokenPost("/api/authorize/useractivate", body, &res)
-	if result != nil {
-		return
-	}
-	resResult = &res
+	resResult, result = call[UserActivateResult](ah, "/api/authorize/useractivate", &uidRequest{UID: uid})
 	return
 }
 
@@ -773,40 +817,19 @@ type GetUserUpdateResult struct {
 
 // GetUserUpdate 获取获取用户更新信息
 func (ah *AuthorizeHandle) GetUserUpdate(uid string) (resResult *GetUserUpdateResult, result *ErrorResult) {
-	body := map[string]interface{}{
-		"ServiceIdentify": ah.cfg.ServiceIdentify,
-		"UID":             uid,
-	}
-
-	var res GetUserUpdateResult
-
-	result = ah.tokenPost("/api/authorize/getuserupdate", body, &res)
-	if result != nil {
-		return
-	}
-	resResult = &res
+	resResult, result = call[GetUserUpdateResult](ah, "/api/authorize/getuserupdate", &uidRequest{UID: uid})
 	return
 }
 
 // DelStaffUser 删除学工用户
 func (ah *AuthorizeHandle) DelStaffUser(uid string) (result *ErrorResult) {
-	body := map[string]interface{}{
-		"ServiceIdentify": ah.cfg.ServiceIdentify,
-		"UID":             uid,
-	}
-
-	result = ah.tokenPost("/api/authorize/delstaffuser", body, nil)
+	_, result = call[struct{}](ah, "/api/authorize/delstaffuser", &uidRequest{UID: uid})
 	return
 }
 
 // UpdateAuthStatus 更新用户认证状态
 func (ah *AuthorizeHandle) UpdateAuthStatus(uid string) (result *ErrorResult) {
-	body := map[string]interface{}{
-		"ServiceIdentify": ah.cfg.ServiceIdentify,
-		"UID":             uid,
-	}
-
-	result = ah.tokenPost("/api/authorize/updateauthstatus", body, nil)
+	_, result = call[struct{}](ah, "/api/authorize/updateauthstatus", &uidRequest{UID: uid})
 	return
 }
 
@@ -817,16 +840,14 @@ func (ah *AuthorizeHandle) GetAntUIDList(service string, uids ...string) (auids
 		svc = service
 	}
 
-	body := map[string]interface{}{
-		"ServiceIdentify": svc,
-		"UID":             uids,
-	}
+	body := &struct {
+		ServiceIdentify string
+		UID             []string
+	}{svc, uids}
 
-	var res struct {
+	res, result := call[struct {
 		ANTUID []string
-	}
-
-	result = ah.tokenPost("/api/authorize/getantuser", body, &res)
+	}](ah, "/api/authorize/getantuser", body)
 	if result != nil {
 		return
 	}
@@ -843,11 +864,9 @@ func (ah *AuthorizeHandle) GetAntUIDByUniversity(userID, university string) (uid
 		University:      university,
 	}
 
-	var res struct {
+	res, result := call[struct {
 		UID string
-	}
-
-	result = ah.tokenPost("/api/authorize/antuidbyuniversity", body, &res)
+	}](ah, "/api/authorize/antuidbyuniversity", body)
 	if result != nil {
 		return
 	}
@@ -0,0 +1,227 @@
+package asapi
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Checkpoint 批量处理的断点记录
+type Checkpoint interface {
+	// IsDone 检查用户是否已经处理完成
+	IsDone(uid string) bool
+	// MarkDone 记录用户已处理完成
+	MarkDone(uid string) error
+}
+
+// NewFileCheckpoint 创建基于文件的断点记录
+// 文件中每行记录一个已完成的用户ID，文件已存在时加载已完成的记录
+func NewFileCheckpoint(name string) (*FileCheckpoint, error) {
+	cp := &FileCheckpoint{
+		done: make(map[string]struct{}),
+	}
+
+	if f, err := os.Open(name); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if uid := strings.TrimSpace(scanner.Text()); uid != "" {
+				cp.done[uid] = struct{}{}
+			}
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	cp.file = f
+
+	return cp, nil
+}
+
+// FileCheckpoint 基于文件的断点记录
+type FileCheckpoint struct {
+	lock sync.Mutex
+	done map[string]struct{}
+	file *os.File
+}
+
+// IsDone 检查用户是否已经处理完成
+func (cp *FileCheckpoint) IsDone(uid string) bool {
+	cp.lock.Lock()
+	_, ok := cp.done[uid]
+	cp.lock.Unlock()
+	return ok
+}
+
+// MarkDone 记录用户已处理完成
+func (cp *FileCheckpoint) MarkDone(uid string) error {
+	cp.lock.Lock()
+	defer cp.lock.Unlock()
+	if _, ok := cp.done[uid]; ok {
+		return nil
+	}
+	if _, err := cp.file.WriteString(uid + "\n"); err != nil {
+		return err
+	}
+	cp.done[uid] = struct{}{}
+	return nil
+}
+
+// Close 关闭断点记录文件
+func (cp *FileCheckpoint) Close() error {
+	return cp.file.Close()
+}
+
+// BatchOptions 批量处理参数
+type BatchOptions struct {
+	Concurrency int        // 并发请求数量，默认10
+	Checkpoint  Checkpoint // 断点记录，为nil时不记录
+	StopOnError bool       // 发生错误时停止后续处理
+}
+
+// BatchResult 单条记录的处理结果
+type BatchResult struct {
+	Index   int          // 记录在输入中的序号
+	UID     string       // 用户ID
+	Skipped bool         // 断点记录中已完成，跳过处理
+	Result  *ErrorResult // 错误结果，成功时为nil
+}
+
+// BatchReport 批量处理结果汇总
+type BatchReport struct {
+	Total     int            // 处理的记录总数
+	Succeeded int            // 成功数量
+	Failed    int            // 失败数量
+	Skipped   int            // 跳过数量
+	Failures  []*BatchResult // 失败的记录
+}
+
+func (r *BatchReport) add(res *BatchResult) {
+	r.Total++
+	switch {
+	case res.Skipped:
+		r.Skipped++
+	case res.Result != nil:
+		r.Failed++
+		r.Failures = append(r.Failures, res)
+	default:
+		r.Succeeded++
+	}
+}
+
+// BatchAddStaffUser 批量增加学工用户
+func (ah *AuthorizeHandle) BatchAddStaffUser(ctx context.Context, reqs []*AddStaffUserRequest, opts *BatchOptions) *BatchReport {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c := make(chan *AddStaffUserRequest)
+	go func() {
+		defer close(c)
+		for _, req := range reqs {
+			select {
+			case c <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ah.StreamAddStaffUser(ctx, c, opts, nil)
+}
+
+// StreamAddStaffUser 从通道中读取并批量增加学工用户，直到通道关闭或ctx结束
+// fn 每条记录处理完成后的回调（可能被并发调用），可以为nil
+func (ah *AuthorizeHandle) StreamAddStaffUser(ctx context.Context, reqs <-chan *AddStaffUserRequest, opts *BatchOptions, fn func(*BatchResult)) *BatchReport {
+	if opts == nil {
+		opts = new(BatchOptions)
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 10
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		report BatchReport
+		lock   sync.Mutex
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, concurrency)
+	)
+
+	done := func(res *BatchResult) {
+		lock.Lock()
+		report.add(res)
+		lock.Unlock()
+		if fn != nil {
+			fn(res)
+		}
+		if res.Result != nil && opts.StopOnError {
+			cancel()
+		}
+	}
+
+	for index := 0; ; index++ {
+		var (
+			req *AddStaffUserRequest
+			ok  bool
+		)
+		select {
+		case req, ok = <-reqs:
+		case <-ctx.Done():
+		}
+		if !ok {
+			break
+		}
+		if req == nil {
+			done(&BatchResult{Index: index, Result: NewErrorResult("增加学工用户的请求为空", http.StatusBadRequest)})
+			continue
+		}
+
+		if cp := opts.Checkpoint; cp != nil && cp.IsDone(req.UID) {
+			done(&BatchResult{Index: index, UID: req.UID, Skipped: true})
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(index int, req *AddStaffUserRequest) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			res := &BatchResult{
+				Index:  index,
+				UID:    req.UID,
+				Result: ah.WithContext(ctx).AddStaffUser(req),
+			}
+			if res.Result == nil && opts.Checkpoint != nil {
+				if err := opts.Checkpoint.MarkDone(req.UID); err != nil {
+					res.Result = NewErrorResult(err.Error())
+				}
+			}
+			done(res)
+		}(index, req)
+	}
+
+	wg.Wait()
+	return &report
+}
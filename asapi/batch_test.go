@@ -0,0 +1,67 @@
+package asapi_test
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/asapi/fakeas"
+)
+
+func TestBatchAddStaffUser(t *testing.T) {
+	var calls int32
+	s := &fakeas.Server{}
+	s.AddStaffUser = func(req *asapi.AddStaffUserRequest) *asapi.ErrorResult {
+		atomic.AddInt32(&calls, 1)
+		if req.UID == "bad" {
+			return asapi.NewErrorResult("invalid user", http.StatusBadRequest)
+		}
+		return nil
+	}
+	ah := newFakeHandle(t, s, nil)
+
+	cp, err := asapi.NewFileCheckpoint(filepath.Join(t.TempDir(), "checkpoint"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cp.Close()
+
+	reqs := []*asapi.AddStaffUserRequest{{UID: "u1"}, {UID: "bad"}, {UID: "u2"}}
+	report := ah.BatchAddStaffUser(context.Background(), reqs, &asapi.BatchOptions{
+		Concurrency: 2,
+		Checkpoint:  cp,
+	})
+	if report.Total != 3 || report.Succeeded != 2 || report.Failed != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if f := report.Failures[0]; f.UID != "bad" || f.Index != 1 || f.Result.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected failure: %+v", f)
+	}
+
+	// 从断点恢复时只重试失败的记录
+	report = ah.BatchAddStaffUser(context.Background(), reqs, &asapi.BatchOptions{Checkpoint: cp})
+	if report.Skipped != 2 || report.Failed != 1 {
+		t.Fatalf("unexpected resumed report: %+v", report)
+	}
+	if calls != 4 {
+		t.Fatalf("unexpected request count: %d", calls)
+	}
+}
+
+func TestBatchAddStaffUserNil(t *testing.T) {
+	s := &fakeas.Server{}
+	s.AddStaffUser = func(*asapi.AddStaffUserRequest) *asapi.ErrorResult { return nil }
+	ah := newFakeHandle(t, s, nil)
+
+	// 空的请求记录为失败，不影响其他记录
+	report := ah.BatchAddStaffUser(context.Background(), []*asapi.AddStaffUserRequest{{UID: "u1"}, nil}, nil)
+	if report.Total != 2 || report.Succeeded != 1 || report.Failed != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if f := report.Failures[0]; f.Index != 1 || f.Result.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected failure: %+v", f)
+	}
+}
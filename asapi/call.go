@@ -0,0 +1,102 @@
+package asapi
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Call 使用访问令牌向授权服务的router发送POST请求，req序列化为JSON请求体，响应解析为Resp，
+// 用于调用SDK尚未封装的接口。请求中没有ServiceIdentify或为空时使用授权处理的服务标识，
+// req实现RequestReader时按路由缓存响应，Resp为struct{}时忽略响应内容。
+// 请求与其他方法一样经过频率限制、节点故障切换、链路追踪、指标和日志处理
+func Call[Req, Resp any](ctx context.Context, ah *AuthorizeHandle, router string, req Req) (*Resp, *ErrorResult) {
+	if ctx != nil {
+		ah = ah.WithContext(ctx)
+	}
+	return call[Resp](ah, router, req)
+}
+
+func call[Resp any](ah *AuthorizeHandle, router string, req interface{}) (resp *Resp, result *ErrorResult) {
+	body, err := ah.requestBody(req)
+	if err != nil {
+		result = NewErrorResult(err.Error())
+		return
+	}
+
+	resp = new(Resp)
+	if _, ok := interface{}(resp).(*struct{}); ok {
+		result = ah.tokenPost(router, body, nil)
+		return
+	}
+
+	var data json.RawMessage
+	if result = ah.tokenPost(router, body, &data); result != nil {
+		resp = nil
+		return
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, resp); err != nil {
+			resp, result = nil, NewErrorResult(err.Error())
+		}
+	}
+	return
+}
+
+// requestBody 将请求转换为JSON对象并设置服务标识，req实现RequestReader时保留缓存参数
+func (ah *AuthorizeHandle) requestBody(req interface{}) (interface{}, error) {
+	buf, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields jsonBody
+	if err := json.Unmarshal(buf, &fields); err != nil || fields == nil {
+		// 不是JSON对象时原样发送
+		return req, nil
+	}
+	var identify string
+	if v, ok := fields["ServiceIdentify"]; ok {
+		json.Unmarshal(v, &identify)
+	}
+	if identify == "" {
+		fields["ServiceIdentify"], _ = json.Marshal(ah.cfg.ServiceIdentify)
+	}
+
+	if reader, ok := req.(RequestReader); ok {
+		return &cachedBody{RequestReader: reader, fields: fields}, nil
+	}
+	return fields, nil
+}
+
+// jsonBody 已序列化的请求字段
+type jsonBody map[string]json.RawMessage
+
+// uid 请求中的用户ID，用于日志
+func (b jsonBody) uid() (uid string) {
+	json.Unmarshal(b["UID"], &uid)
+	return
+}
+
+// cachedBody 实现RequestReader的请求
+type cachedBody struct {
+	RequestReader
+	fields jsonBody
+}
+
+// MarshalJSON 实现json.Marshaler接口
+func (b *cachedBody) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.fields)
+}
+
+// uidRequest 只包含用户ID的请求
+type uidRequest struct {
+	ServiceIdentify string
+	UID             string
+}
+
+// passwordRequest 包含用户ID和密码的请求
+type passwordRequest struct {
+	ServiceIdentify string
+	UID             string
+	Password        string
+}
@@ -0,0 +1,59 @@
+package asapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/asapi/fakeas"
+)
+
+func TestCall(t *testing.T) {
+	var bodies []map[string]interface{}
+	mux := http.NewServeMux()
+	mux.Handle("/", (&fakeas.Server{}).Handler())
+	mux.HandleFunc("/api/authorize/custom", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("AccessToken") != fakeas.DefaultToken {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		w.Write([]byte(`{"Name":"张三"}`))
+	})
+	ah := newFakeHandleFor(t, mux, nil)
+
+	type request struct {
+		ServiceIdentify string
+		UID             string
+	}
+	type response struct {
+		Name string
+	}
+
+	resp, result := asapi.Call[request, response](context.Background(), ah, "/api/authorize/custom", request{UID: "u1"})
+	if result != nil {
+		t.Fatal(result)
+	}
+	if resp.Name != "张三" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	// 指定的服务标识不被覆盖，Resp为struct{}时忽略响应
+	_, result = asapi.Call[request, struct{}](context.Background(), ah, "/api/authorize/custom", request{ServiceIdentify: "OTHER", UID: "u2"})
+	if result != nil {
+		t.Fatal(result)
+	}
+
+	if len(bodies) != 2 || bodies[0]["ServiceIdentify"] != "TEST" || bodies[0]["UID"] != "u1" ||
+		bodies[1]["ServiceIdentify"] != "OTHER" {
+		t.Fatalf("unexpected request bodies: %v", bodies)
+	}
+
+	if _, result = asapi.Call[request, response](context.Background(), ah, "/api/authorize/missing", request{}); result == nil || result.Code != http.StatusNotFound {
+		t.Fatalf("unexpected result: %v", result)
+	}
+}
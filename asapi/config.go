@@ -2,27 +2,95 @@ package asapi
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/antlinker/sdk/validation"
 )
 
 // Config 配置参数
 type Config struct {
-	ASURL           string // 授权服务URL
-	ClientID        string // 客户端ID
-	ClientSecret    string // 客户端秘钥
-	ServiceIdentify string // 服务标识
-	IsEnabledCache  bool   // 是否启用缓存
-	CacheGCInterval int    // 缓存gc间隔(单位秒)
-	MaxConns        int
+	ASURL               string   // 授权服务URL
+	ASURLs              []string // 其他授权服务节点的URL，与ASURL一起进行负载均衡和故障切换
+	Balance             string   // 多个节点的负载均衡策略：round_robin（默认）或least_latency
+	HealthCheckInterval int      // 主动检查节点是否可用的间隔(单位秒)，0表示只根据请求结果判断
+	FailTimeout         int      // 请求失败的节点在该时间内不被优先选择(单位秒)，默认30
+	ClientID            string   // 客户端ID
+	ClientSecret        string   // 客户端秘钥
+	ServiceIdentify     string   // 服务标识
+	IsEnabledCache      bool     // 是否启用缓存
+	CacheGCInterval     int      // 缓存gc间隔(单位秒)
+	MaxConns            int
+	RateLimit           *RateLimitConfig           // 客户端请求频率限制，为nil时不限制
+	Hedge               *HedgeConfig               // 验证令牌的对冲请求，为nil时不发送对冲请求
+	TLS                 *TLSConfig                 // 请求授权服务使用的TLS配置，为nil时使用默认配置
+	ValidateRequests    bool                       // 增加、编辑用户前校验手机号、身份证号等参数，参考validation包
+	PasswordPolicy      *validation.PasswordPolicy // 修改密码、增加用户前检查密码强度，为nil时不检查
+}
+
+// Validate 检查必填的配置参数
+func (c *Config) Validate() error {
+	switch {
+	case c.ASURL == "" && len(c.ASURLs) == 0:
+		return errors.New("asapi: 未指定授权服务URL(ASURL)")
+	case c.ClientID == "":
+		return errors.New("asapi: 未指定客户端ID(ClientID)")
+	case c.ClientSecret == "":
+		return errors.New("asapi: 未指定客户端秘钥(ClientSecret)")
+	case c.CacheGCInterval < 0:
+		return errors.New("asapi: 缓存gc间隔(CacheGCInterval)不能小于0")
+	case c.Balance != "" && c.Balance != BalanceRoundRobin && c.Balance != BalanceLeastLatency:
+		return fmt.Errorf("asapi: 不支持的负载均衡策略(Balance)：%s", c.Balance)
+	case c.HealthCheckInterval < 0 || c.FailTimeout < 0:
+		return errors.New("asapi: 健康检查间隔(HealthCheckInterval)和失败超时(FailTimeout)不能小于0")
+	}
+	if c.Hedge != nil && c.Hedge.Delay < 0 {
+		return errors.New("asapi: 对冲请求等待时间(Hedge.Delay)不能小于0")
+	}
+	if c.TLS != nil {
+		if err := c.TLS.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.RateLimit != nil {
+		return c.RateLimit.Validate()
+	}
+	return nil
 }
 
 // GetURL 获取请求的URL
 func (c *Config) GetURL(router string) string {
+	var addr string
+	if urls := c.urls(); len(urls) > 0 {
+		addr = urls[0]
+	}
+	return joinURL(addr, router)
+}
+
+// urls 去重后的所有授权服务节点URL，ASURL在最前
+func (c *Config) urls() []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, u := range append([]string{c.ASURL}, c.ASURLs...) {
+		u = strings.TrimSuffix(u, "/")
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// joinURL 拼接授权服务URL和路由
+func joinURL(addr, router string) string {
 	var buf bytes.Buffer
-	if l := len(c.ASURL); c.ASURL[l-1] == '/' {
-		c.ASURL = c.ASURL[:l-1]
+	if l := len(addr); l > 0 && addr[l-1] == '/' {
+		addr = addr[:l-1]
 	}
-	buf.WriteString(c.ASURL)
+	buf.WriteString(addr)
 	if l := len(router); l > 0 && router[0] != '/' {
 		buf.WriteByte('/')
 	}
@@ -51,3 +119,16 @@ func NewErrorResult(msg string, code ...int) *ErrorResult {
 	}
 	return result
 }
+
+// IsUserNotFound 检查是否为授权服务返回的用户不存在的错误（错误码11）
+// 只根据授权服务的错误码判断，路由错误、代理返回的404等不属于用户不存在
+func (er *ErrorResult) IsUserNotFound() bool {
+	if er == nil {
+		return false
+	}
+	var inner ErrorResult
+	if err := json.Unmarshal([]byte(er.Message), &inner); err != nil {
+		return false
+	}
+	return inner.Code == 11
+}
@@ -0,0 +1,188 @@
+package asapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/antlinker/sdk/utils"
+)
+
+// 多个授权服务节点的负载均衡策略
+const (
+	BalanceRoundRobin   = "round_robin"   // 轮询
+	BalanceLeastLatency = "least_latency" // 选择平均响应时间最短的节点
+)
+
+// defaultFailTimeout 请求失败的节点默认不被选择的时间
+const defaultFailTimeout = 30 * time.Second
+
+// endpoint 授权服务节点
+type endpoint struct {
+	url       string
+	latency   time.Duration // 成功请求的平均响应时间
+	downUntil time.Time     // 节点不可用的截止时间
+}
+
+// newEndpoints 根据配置参数创建授权服务节点
+func newEndpoints(cfg *Config) *endpoints {
+	e := &endpoints{
+		balance:     cfg.Balance,
+		failTimeout: time.Duration(cfg.FailTimeout) * time.Second,
+	}
+	if e.failTimeout <= 0 {
+		e.failTimeout = defaultFailTimeout
+	}
+	for _, u := range cfg.urls() {
+		e.list = append(e.list, &endpoint{url: u})
+	}
+	if len(e.list) == 0 {
+		e.list = append(e.list, &endpoint{})
+	}
+	return e
+}
+
+// endpoints 授权服务节点的负载均衡、健康检查和故障切换
+type endpoints struct {
+	list        []*endpoint
+	balance     string
+	failTimeout time.Duration
+	next        uint32
+	lock        sync.Mutex
+	stop        chan struct{}
+	stopOnce    sync.Once
+}
+
+// order 返回请求时依次尝试的节点，可用的节点按负载均衡策略排列在前，不可用的节点按恢复时间排列在后
+func (e *endpoints) order() []*endpoint {
+	if len(e.list) == 1 {
+		return e.list
+	}
+
+	n := atomic.AddUint32(&e.next, 1) - 1
+	now := time.Now()
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	var up, down []*endpoint
+	for i := range e.list {
+		ep := e.list[(int(n)+i)%len(e.list)]
+		if ep.downUntil.After(now) {
+			down = append(down, ep)
+		} else {
+			up = append(up, ep)
+		}
+	}
+	if e.balance == BalanceLeastLatency {
+		sort.SliceStable(up, func(i, j int) bool { return up[i].latency < up[j].latency })
+	}
+	sort.SliceStable(down, func(i, j int) bool { return down[i].downUntil.Before(down[j].downUntil) })
+	return append(up, down...)
+}
+
+// observe 记录节点的请求结果，请求失败的节点在failTimeout内不被优先选择
+func (e *endpoints) observe(ep *endpoint, d time.Duration, failed bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if failed {
+		ep.downUntil = time.Now().Add(e.failTimeout)
+		return
+	}
+	ep.downUntil = time.Time{}
+	if ep.latency == 0 {
+		ep.latency = d
+	} else {
+		ep.latency = (ep.latency*4 + d) / 5
+	}
+}
+
+// check 检查所有节点是否可用，返回最后一个不可用节点的错误，有一个节点可用时返回nil
+func (e *endpoints) check(ctx context.Context, rt http.RoundTripper) (err error) {
+	cli := &http.Client{Transport: rt}
+	var ok bool
+	for _, ep := range e.list {
+		start := time.Now()
+		verr := utils.CheckHTTP(ctx, cli, joinURL(ep.url, ""))
+		e.observe(ep, time.Since(start), verr != nil)
+		if verr != nil {
+			err = verr
+		} else {
+			ok = true
+		}
+	}
+	if ok {
+		return nil
+	}
+	return
+}
+
+// startHealthCheck 按interval主动检查节点是否可用，直到调用stopHealthCheck
+func (e *endpoints) startHealthCheck(interval time.Duration, rt func() http.RoundTripper) {
+	if interval <= 0 || e.stop != nil {
+		return
+	}
+	e.stop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				e.check(ctx, rt())
+				cancel()
+			case <-e.stop:
+				return
+			}
+		}
+	}()
+}
+
+// stopHealthCheck 停止主动健康检查
+func (e *endpoints) stopHealthCheck() {
+	if e.stop == nil {
+		return
+	}
+	e.stopOnce.Do(func() { close(e.stop) })
+}
+
+// nodeError 请求授权服务节点失败，可以切换到其他节点
+type nodeError struct {
+	err    error
+	status int
+}
+
+func (e *nodeError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return http.StatusText(e.status)
+}
+
+// canFailover 请求节点失败后是否可以向其他节点重新发送，
+// 幂等的GET请求总是可以重新发送，其他请求只在确定未被处理（连接失败或503）时重新发送
+func canFailover(method string, err *nodeError) bool {
+	if err == nil {
+		return false
+	}
+	if method == http.MethodGet || err.status == http.StatusServiceUnavailable {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err.err, &opErr) && opErr.Op == "dial"
+}
+
+// unavailable 响应状态码是否表示节点不可用
+func unavailable(status int) bool {
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
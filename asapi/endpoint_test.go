@@ -0,0 +1,85 @@
+package asapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func newEndpointTestServer(calls *int32, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		switch r.URL.Path {
+		case "/oauth2/token":
+			w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+		default:
+			w.Write([]byte(`{"user_id":"user","client_id":"client","expires_in":3600}`))
+		}
+	}))
+}
+
+func TestEndpointFailover(t *testing.T) {
+	var badCalls, goodCalls int32
+	bad := newEndpointTestServer(&badCalls, http.StatusServiceUnavailable)
+	defer bad.Close()
+	good := newEndpointTestServer(&goodCalls, http.StatusOK)
+	defer good.Close()
+
+	// 已关闭的节点连接失败
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	ah := NewAuthorizeHandle(&Config{
+		ASURL:           down.URL,
+		ASURLs:          []string{bad.URL, good.URL},
+		ServiceIdentify: "TEST",
+	})
+	defer ah.Close()
+
+	if _, result := ah.GetToken(); result != nil {
+		t.Fatal(result)
+	}
+	if _, _, result := ah.VerifyToken("token"); result != nil {
+		t.Fatal(result)
+	}
+	if badCalls != 1 {
+		t.Fatalf("unavailable endpoint should be skipped after failure, calls: %d", badCalls)
+	}
+	if goodCalls != 2 {
+		t.Fatalf("unexpected calls: %d", goodCalls)
+	}
+
+	if err := ah.HealthCheck(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEndpointOrder(t *testing.T) {
+	e := newEndpoints(&Config{ASURL: "http://a", ASURLs: []string{"http://b", "http://a/"}, Balance: BalanceLeastLatency})
+	if len(e.list) != 2 {
+		t.Fatalf("unexpected endpoints: %d", len(e.list))
+	}
+	e.observe(e.list[0], 200, false)
+	e.observe(e.list[1], 100, false)
+	for i := 0; i < 2; i++ {
+		if eps := e.order(); eps[0].url != "http://b" {
+			t.Fatalf("unexpected order: %s", eps[0].url)
+		}
+	}
+
+	e.observe(e.list[1], 0, true)
+	if eps := e.order(); eps[0].url != "http://a" || eps[1].url != "http://b" {
+		t.Fatalf("unexpected order: %s, %s", eps[0].url, eps[1].url)
+	}
+
+	e = newEndpoints(&Config{ASURL: "http://a", ASURLs: []string{"http://b"}})
+	if e.order()[0] == e.order()[0] {
+		t.Fatal("round robin should rotate endpoints")
+	}
+}
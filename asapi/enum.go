@@ -0,0 +1,175 @@
+package asapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// UserType 用户类型
+type UserType string
+
+// 用户类型
+const (
+	UserTypeStudent UserType = "1" // 学生
+	UserTypeTeacher UserType = "2" // 老师
+)
+
+// String 返回用户类型的名称
+func (t UserType) String() string {
+	switch t {
+	case UserTypeStudent:
+		return "学生"
+	case UserTypeTeacher:
+		return "老师"
+	}
+	return fmt.Sprintf("UserType(%s)", string(t))
+}
+
+// Validate 检查用户类型，未设置时返回nil
+func (t UserType) Validate() error {
+	switch t {
+	case "", UserTypeStudent, UserTypeTeacher:
+		return nil
+	}
+	return fmt.Errorf("asapi: 未知的用户类型(UserType)：%q", string(t))
+}
+
+// UnmarshalJSON 实现json.Unmarshaler接口，兼容授权服务返回的字符串和数字；
+// 未知的用户类型保留原值，以免授权服务增加类型后解析失败，需要时使用Validate检查
+func (t *UserType) UnmarshalJSON(b []byte) error {
+	s, err := unquote(b)
+	if err != nil {
+		return fmt.Errorf("asapi: 无效的用户类型(UserType)：%s", b)
+	}
+	*t = UserType(s)
+	return nil
+}
+
+// Sex 性别
+type Sex string
+
+// 性别
+const (
+	SexFemale Sex = "F" // 女
+	SexMale   Sex = "M" // 男
+)
+
+// ParseSex 解析性别，不区分大小写，空字符串表示未设置
+func ParseSex(s string) (Sex, error) {
+	v := normalizeSex(s)
+	if err := v.Validate(); err != nil {
+		return "", err
+	}
+	return v, nil
+}
+
+func normalizeSex(s string) Sex {
+	return Sex(strings.ToUpper(strings.TrimSpace(s)))
+}
+
+// String 返回性别的名称
+func (s Sex) String() string {
+	switch s {
+	case SexFemale:
+		return "女"
+	case SexMale:
+		return "男"
+	}
+	return fmt.Sprintf("Sex(%s)", string(s))
+}
+
+// Set 实现flag.Value接口
+func (s *Sex) Set(v string) (err error) {
+	*s, err = ParseSex(v)
+	return
+}
+
+// Validate 检查性别，未设置时返回nil
+func (s Sex) Validate() error {
+	switch s {
+	case "", SexFemale, SexMale:
+		return nil
+	}
+	return fmt.Errorf("asapi: 未知的性别(Sex)：%q", string(s))
+}
+
+// UnmarshalJSON 实现json.Unmarshaler接口，与ParseSex一样不区分大小写；
+// 未知的性别保留原值，需要时使用Validate检查
+func (s *Sex) UnmarshalJSON(b []byte) error {
+	v, err := unquote(b)
+	if err != nil {
+		return fmt.Errorf("asapi: 无效的性别(Sex)：%s", b)
+	}
+	*s = normalizeSex(v)
+	return nil
+}
+
+// LoginModel 密码模式的登录模式
+type LoginModel int
+
+// 登录模式
+const (
+	LoginModelPhone    LoginModel = 1 // 手机号、身份证号登录
+	LoginModelUserCode LoginModel = 2 // 学校、学号登录
+	LoginModelUpgrade  LoginModel = 9 // 使用其他客户端的令牌升级，由GetUpgradeToken使用
+)
+
+// String 返回登录模式的名称
+func (m LoginModel) String() string {
+	switch m {
+	case LoginModelPhone:
+		return "手机号、身份证号登录"
+	case LoginModelUserCode:
+		return "学校、学号登录"
+	case LoginModelUpgrade:
+		return "升级令牌"
+	}
+	return fmt.Sprintf("LoginModel(%d)", int(m))
+}
+
+// Validate 检查登录模式
+func (m LoginModel) Validate() error {
+	switch m {
+	case LoginModelPhone, LoginModelUserCode, LoginModelUpgrade:
+		return nil
+	}
+	return fmt.Errorf("asapi: 未知的登录模式(LoginModel)：%d", int(m))
+}
+
+// UnmarshalJSON 实现json.Unmarshaler接口，不是整数时返回错误；
+// 未知的登录模式保留原值，需要时使用Validate检查
+func (m *LoginModel) UnmarshalJSON(b []byte) error {
+	s, err := unquote(b)
+	if err != nil {
+		return fmt.Errorf("asapi: 无效的登录模式(LoginModel)：%s", b)
+	}
+	if s == "" {
+		*m = 0
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("asapi: 无效的登录模式(LoginModel)：%s", b)
+	}
+	*m = LoginModel(n)
+	return nil
+}
+
+// unquote 将JSON字符串、数字或null转换为字符串，null返回空字符串
+func unquote(b []byte) (string, error) {
+	switch {
+	case string(b) == "null":
+		return "", nil
+	case len(b) > 0 && b[0] == '"':
+		var s string
+		err := json.Unmarshal(b, &s)
+		return s, err
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return "", err
+	}
+	return n.String(), nil
+}
@@ -0,0 +1,66 @@
+package asapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEnumJSON(t *testing.T) {
+	var info UserActivateResult
+	if err := json.Unmarshal([]byte(`{"UserType":2,"Sex":"F"}`), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.UserType != UserTypeTeacher || info.Sex != SexFemale {
+		t.Fatalf("unexpected result: %+v", info)
+	}
+	if info.UserType.String() != "老师" || info.Sex.String() != "女" {
+		t.Fatalf("unexpected names: %s, %s", info.UserType, info.Sex)
+	}
+
+	// 未知的值保留原值，由调用方决定是否检查
+	if err := json.Unmarshal([]byte(`{"UserType":"3","Sex":"x"}`), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.UserType != "3" || info.UserType.Validate() == nil || info.Sex != "X" || info.Sex.Validate() == nil {
+		t.Fatalf("unexpected result: %+v", info)
+	}
+	// 与ParseSex一样不区分大小写
+	if err := json.Unmarshal([]byte(`{"Sex":"f"}`), &info); err != nil || info.Sex != SexFemale {
+		t.Fatalf("unexpected sex: %q, %v", info.Sex, err)
+	}
+	if err := json.Unmarshal([]byte(`{"UserType":{}}`), &info); err == nil || !strings.Contains(err.Error(), "UserType") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var req PasswordRequest
+	if err := json.Unmarshal([]byte(`{"LoginModel":7}`), &req); err != nil || req.LoginModel != 7 || req.LoginModel.Validate() == nil {
+		t.Fatalf("unexpected login model: %v, %v", req.LoginModel, err)
+	}
+
+	buf, _ := json.Marshal(&PasswordRequest{LoginModel: LoginModelUserCode})
+	if !strings.Contains(string(buf), `"LoginModel":2`) {
+		t.Fatalf("unexpected json: %s", buf)
+	}
+}
+
+func TestEnumValidate(t *testing.T) {
+	if sex, err := ParseSex(" m "); err != nil || sex != SexMale {
+		t.Fatalf("unexpected sex: %q, %v", sex, err)
+	}
+	if _, err := ParseSex("X"); err == nil {
+		t.Fatal("expected invalid sex error")
+	}
+	if err := LoginModel(0).Validate(); err == nil {
+		t.Fatal("expected invalid login model error")
+	}
+
+	ah := NewAuthorizeHandle(&Config{ASURL: "http://127.0.0.1:0", ServiceIdentify: "TEST"})
+	// 无效的参数在发送请求前返回错误
+	if result := ah.AddStaffUser(&AddStaffUserRequest{UID: "u1", Sex: "X"}); result == nil || !strings.Contains(result.Message, "Sex") {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if _, result := ah.GetAccessTokenByPassword(PasswordRequest{LoginModel: 3}); result == nil || !strings.Contains(result.Message, "LoginModel") {
+		t.Fatalf("unexpected result: %v", result)
+	}
+}
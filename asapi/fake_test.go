@@ -0,0 +1,35 @@
+package asapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/asapi/fakeas"
+)
+
+// newFakeHandle 启动模拟的授权服务并创建请求它的授权处理，测试结束时关闭
+// cfg为nil时使用默认配置，ASURL总是指向模拟的授权服务
+func newFakeHandle(t *testing.T, s *fakeas.Server, cfg *asapi.Config) *asapi.AuthorizeHandle {
+	t.Helper()
+	return newFakeHandleFor(t, s.Handler(), cfg)
+}
+
+// newFakeHandleFor 与newFakeHandle相同，h通常是包装了fakeas.Server的处理，用于模拟响应头等
+func newFakeHandleFor(t *testing.T, h http.Handler, cfg *asapi.Config) *asapi.AuthorizeHandle {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	if cfg == nil {
+		cfg = new(asapi.Config)
+	}
+	cfg.ASURL = srv.URL
+	if cfg.ServiceIdentify == "" {
+		cfg.ServiceIdentify = "TEST"
+	}
+	ah := asapi.NewAuthorizeHandle(cfg)
+	t.Cleanup(func() { ah.Close() })
+	return ah
+}
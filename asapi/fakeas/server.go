@@ -0,0 +1,108 @@
+// Package fakeas 模拟授权服务，用于测试调用授权服务的代码
+//
+// 接口处理由asgen根据asapi/api.yaml生成，未设置处理函数的接口返回404：
+//
+//	s := &fakeas.Server{}
+//	s.GetUserVersion = func(req map[string]interface{}) (*asapi.GetUserVersionResult, *asapi.ErrorResult) {
+//		return &asapi.GetUserVersionResult{Version: 2}, nil
+//	}
+//	srv := httptest.NewServer(s.Handler())
+//	defer srv.Close()
+//	ah := asapi.NewAuthorizeHandle(&asapi.Config{ASURL: srv.URL, ServiceIdentify: "TEST"})
+package fakeas
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/antlinker/sdk/asapi"
+)
+
+// DefaultToken 默认的访问令牌
+const DefaultToken = "fake-token"
+
+// Server 模拟授权服务
+type Server struct {
+	Handlers
+	Token string // 获取令牌接口返回的访问令牌，为空时使用DefaultToken
+}
+
+func (s *Server) token() string {
+	if s.Token == "" {
+		return DefaultToken
+	}
+	return s.Token
+}
+
+// Handler 返回模拟授权服务的http.Handler，包括获取令牌接口和已设置的接口处理
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token": s.token(),
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	})
+	s.Handlers.register(s, mux)
+	return mux
+}
+
+// handle 解析请求并调用fn，将结果写入响应
+func handle[Req, Resp any](s *Server, fn func(req Req) (Resp, *asapi.ErrorResult)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decode[Req](s, w, r)
+		if !ok {
+			return
+		}
+		resp, result := fn(req)
+		if result != nil {
+			writeError(w, result)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// handleNoResult 解析请求并调用没有响应内容的fn
+func handleNoResult[Req any](s *Server, fn func(req Req) *asapi.ErrorResult) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decode[Req](s, w, r)
+		if !ok {
+			return
+		}
+		if result := fn(req); result != nil {
+			writeError(w, result)
+			return
+		}
+		writeJSON(w, http.StatusOK, struct{}{})
+	})
+}
+
+// decode 检查访问令牌并解析请求，Req为指针或map
+func decode[Req any](s *Server, w http.ResponseWriter, r *http.Request) (req Req, ok bool) {
+	if r.Header.Get("AccessToken") != s.token() {
+		http.Error(w, "invalid access token", http.StatusUnauthorized)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ok = true
+	return
+}
+
+func writeError(w http.ResponseWriter, result *asapi.ErrorResult) {
+	code := result.Code
+	if code == 0 {
+		code = http.StatusBadRequest
+	}
+	http.Error(w, result.Message, code)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,56 @@
+package fakeas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/antlinker/sdk/asapi"
+)
+
+func TestServer(t *testing.T) {
+	s := &Server{}
+	s.GetUserVersion = func(req map[string]interface{}) (*asapi.GetUserVersionResult, *asapi.ErrorResult) {
+		if req["UID"] != "u1" || req["ServiceIdentify"] != "TEST" {
+			return nil, asapi.NewErrorResult("unexpected request", http.StatusBadRequest)
+		}
+		return &asapi.GetUserVersionResult{Version: 2}, nil
+	}
+	var added []*asapi.AddStaffUserRequest
+	s.AddStaffUser = func(req *asapi.AddStaffUserRequest) *asapi.ErrorResult {
+		if req.UID == "" {
+			return asapi.NewErrorResult("missing uid")
+		}
+		added = append(added, req)
+		return nil
+	}
+
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	ah := asapi.NewAuthorizeHandle(&asapi.Config{ASURL: srv.URL, ServiceIdentify: "TEST"})
+	defer ah.Close()
+
+	v, result := ah.GetUserVersion("u1")
+	if result != nil {
+		t.Fatal(result)
+	}
+	if v.Version != 2 {
+		t.Fatalf("unexpected version: %+v", v)
+	}
+
+	if result = ah.AddStaffUser(&asapi.AddStaffUserRequest{UID: "u2", Name: "张三"}); result != nil {
+		t.Fatal(result)
+	}
+	if len(added) != 1 || added[0].Name != "张三" {
+		t.Fatalf("unexpected requests: %v", added)
+	}
+	if result = ah.AddStaffUser(&asapi.AddStaffUserRequest{}); result == nil || result.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	// 未设置处理函数的接口返回404
+	if _, result = ah.GetUser("u1"); result == nil || result.Code != http.StatusNotFound {
+		t.Fatalf("unexpected result: %v", result)
+	}
+}
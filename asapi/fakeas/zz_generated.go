@@ -0,0 +1,101 @@
+// Code generated by asgen. DO NOT EDIT.
+
+package fakeas
+
+import (
+	"net/http"
+
+	"github.com/antlinker/sdk/asapi"
+)
+
+// Handlers 模拟授权服务的接口处理，未设置的接口返回404
+type Handlers struct {
+	VerifyLogin           func(req map[string]interface{}) (*asapi.LoginUserInfo, *asapi.ErrorResult)                // /api/authorize/verifylogin 验证登录
+	GetUser               func(req map[string]interface{}) (*asapi.LoginUserInfo, *asapi.ErrorResult)                // /api/authorize/getuser 获取用户信息
+	AddUser               func(req map[string]interface{}) *asapi.ErrorResult                                        // /api/authorize/adduser 增加用户
+	EditUser              func(req map[string]interface{}) *asapi.ErrorResult                                        // /api/authorize/edituser 编辑用户信息
+	DelUser               func(req map[string]interface{}) *asapi.ErrorResult                                        // /api/authorize/deluser 删除用户
+	ModifyPwd             func(req map[string]interface{}) *asapi.ErrorResult                                        // /api/authorize/modifypwd 修改密码
+	CheckDefaultPwd       func(req map[string]interface{}) (map[string]interface{}, *asapi.ErrorResult)              // /api/authorize/checkdefaultpwd 检查默认密码
+	MergeUser             func(req *asapi.AuthorizeMergeUserRequest) *asapi.ErrorResult                              // /api/authorize/mergeuser 合并用户
+	GetStaffParam         func(req *asapi.GetStaffParamRequest) (*asapi.GetAntStaffParamResult, *asapi.ErrorResult)  // /api/authorize/getstaffparam 获取学工参数
+	MergeTELUser          func(req *asapi.AuthorizeMergeTELUserRequest) *asapi.ErrorResult                           // /api/authorize/mergeteluser 合并手机号用户
+	ClearAuth             func(req *asapi.ClearAuthRequest) *asapi.ErrorResult                                       // /api/authorize/clearauth 清理用户认证信息
+	GetUserCode           func(req *asapi.GetUserCodeRequest) (map[string]interface{}, *asapi.ErrorResult)           // /api/authorize/usercode 根据用户ID获取UserCode
+	AddStaffUser          func(req *asapi.AddStaffUserRequest) *asapi.ErrorResult                                    // /api/authorize/addstaffuser 增加学工用户
+	UpdateUserBasic       func(req *asapi.UpdateUserBasicRequest) *asapi.ErrorResult                                 // /api/authorize/updateuserbasic 更新用户基础信息
+	GetUserVersion        func(req map[string]interface{}) (*asapi.GetUserVersionResult, *asapi.ErrorResult)         // /api/authorize/getuserversion 获取用户版本信息
+	UserActivate          func(req map[string]interface{}) (*asapi.UserActivateResult, *asapi.ErrorResult)           // /api/authorize/useractivate 用户激活
+	GetUserUpdate         func(req map[string]interface{}) (*asapi.GetUserUpdateResult, *asapi.ErrorResult)          // /api/authorize/getuserupdate 获取用户更新信息
+	DelStaffUser          func(req map[string]interface{}) *asapi.ErrorResult                                        // /api/authorize/delstaffuser 删除学工用户
+	UpdateAuthStatus      func(req map[string]interface{}) *asapi.ErrorResult                                        // /api/authorize/updateauthstatus 更新用户认证状态
+	GetAntUIDList         func(req map[string]interface{}) (map[string]interface{}, *asapi.ErrorResult)              // /api/authorize/getantuser 获取ANT用户ID列表
+	GetAntUIDByUniversity func(req *asapi.GetAntUIDByUniversityRequest) (map[string]interface{}, *asapi.ErrorResult) // /api/authorize/antuidbyuniversity 根据学校查询ANT用户ID
+}
+
+// register 注册已设置的接口处理
+func (h *Handlers) register(s *Server, mux *http.ServeMux) {
+	if h.VerifyLogin != nil {
+		mux.Handle("/api/authorize/verifylogin", handle(s, h.VerifyLogin))
+	}
+	if h.GetUser != nil {
+		mux.Handle("/api/authorize/getuser", handle(s, h.GetUser))
+	}
+	if h.AddUser != nil {
+		mux.Handle("/api/authorize/adduser", handleNoResult(s, h.AddUser))
+	}
+	if h.EditUser != nil {
+		mux.Handle("/api/authorize/edituser", handleNoResult(s, h.EditUser))
+	}
+	if h.DelUser != nil {
+		mux.Handle("/api/authorize/deluser", handleNoResult(s, h.DelUser))
+	}
+	if h.ModifyPwd != nil {
+		mux.Handle("/api/authorize/modifypwd", handleNoResult(s, h.ModifyPwd))
+	}
+	if h.CheckDefaultPwd != nil {
+		mux.Handle("/api/authorize/checkdefaultpwd", handle(s, h.CheckDefaultPwd))
+	}
+	if h.MergeUser != nil {
+		mux.Handle("/api/authorize/mergeuser", handleNoResult(s, h.MergeUser))
+	}
+	if h.GetStaffParam != nil {
+		mux.Handle("/api/authorize/getstaffparam", handle(s, h.GetStaffParam))
+	}
+	if h.MergeTELUser != nil {
+		mux.Handle("/api/authorize/mergeteluser", handleNoResult(s, h.MergeTELUser))
+	}
+	if h.ClearAuth != nil {
+		mux.Handle("/api/authorize/clearauth", handleNoResult(s, h.ClearAuth))
+	}
+	if h.GetUserCode != nil {
+		mux.Handle("/api/authorize/usercode", handle(s, h.GetUserCode))
+	}
+	if h.AddStaffUser != nil {
+		mux.Handle("/api/authorize/addstaffuser", handleNoResult(s, h.AddStaffUser))
+	}
+	if h.UpdateUserBasic != nil {
+		mux.Handle("/api/authorize/updateuserbasic", handleNoResult(s, h.UpdateUserBasic))
+	}
+	if h.GetUserVersion != nil {
+		mux.Handle("/api/authorize/getuserversion", handle(s, h.GetUserVersion))
+	}
+	if h.UserActivate != nil {
+		mux.Handle("/api/authorize/useractivate", handle(s, h.UserActivate))
+	}
+	if h.GetUserUpdate != nil {
+		mux.Handle("/api/authorize/getuserupdate", handle(s, h.GetUserUpdate))
+	}
+	if h.DelStaffUser != nil {
+		mux.Handle("/api/authorize/delstaffuser", handleNoResult(s, h.DelStaffUser))
+	}
+	if h.UpdateAuthStatus != nil {
+		mux.Handle("/api/authorize/updateauthstatus", handleNoResult(s, h.UpdateAuthStatus))
+	}
+	if h.GetAntUIDList != nil {
+		mux.Handle("/api/authorize/getantuser", handle(s, h.GetAntUIDList))
+	}
+	if h.GetAntUIDByUniversity != nil {
+		mux.Handle("/api/authorize/antuidbyuniversity", handle(s, h.GetAntUIDByUniversity))
+	}
+}
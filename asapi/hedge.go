@@ -0,0 +1,138 @@
+package asapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/astaxie/beego/httplib"
+)
+
+// defaultHedgeDelay 默认的对冲请求等待时间
+const defaultHedgeDelay = 50 * time.Millisecond
+
+// HedgeConfig 对冲请求配置，验证令牌的请求在Delay内未响应时，向URL再发送一个相同的请求，
+// 使用先响应的结果，另一个请求将被取消
+type HedgeConfig struct {
+	URL   string // 接收对冲请求的授权服务URL，为空时使用负载均衡选择的下一个节点
+	Delay int    // 发送对冲请求前等待的时间(单位毫秒)，默认50
+}
+
+func (c *HedgeConfig) delay() time.Duration {
+	if c.Delay == 0 {
+		return defaultHedgeDelay
+	}
+	return time.Duration(c.Delay) * time.Millisecond
+}
+
+// hedgeAttempt 对冲请求中一个请求的结果
+type hedgeAttempt struct {
+	data   json.RawMessage
+	result *ErrorResult
+}
+
+// final 是否为授权服务明确的响应，不需要等待另一个请求
+func (a *hedgeAttempt) final() bool {
+	if a.result == nil {
+		return true
+	}
+	code := a.result.Code
+	return code >= 400 && code < 500 && code != http.StatusTooManyRequests
+}
+
+// hedgedGet 发送幂等的GET请求，未配置Hedge时与request相同
+func (ah *AuthorizeHandle) hedgedGet(router string, reqHandle func(req *httplib.BeegoHTTPRequest) (*httplib.BeegoHTTPRequest, *ErrorResult), v interface{}) (result *ErrorResult) {
+	cfg := ah.cfg.Hedge
+	if cfg == nil {
+		return ah.request(router, http.MethodGet, reqHandle, v)
+	}
+
+	ctx, cancel := context.WithCancel(ah.context())
+	defer cancel()
+	cah := ah.WithContext(ctx)
+	transport := &contextTransport{ctx: ctx, next: ah.tokenHandle().roundTripper()}
+
+	done := make(chan *hedgeAttempt, 2)
+	send := func(ep *endpoint, n int) {
+		a := new(hedgeAttempt)
+		a.result, _ = cah.requestNode(ep, router, http.MethodGet, func(req *httplib.BeegoHTTPRequest) (*httplib.BeegoHTTPRequest, *ErrorResult) {
+			req.SetTransport(transport)
+			if reqHandle != nil {
+				return reqHandle(req)
+			}
+			return req, nil
+		}, &a.data, "attempt", n)
+		done <- a
+	}
+
+	eps := ah.tokenHandle().endpoints.order()
+	primary, hedge := eps[0], eps[0]
+	if cfg.URL != "" {
+		hedge = &endpoint{url: cfg.URL}
+	} else if len(eps) > 1 {
+		hedge = eps[1]
+	}
+
+	go send(primary, 1)
+	timer := time.NewTimer(cfg.delay())
+	defer timer.Stop()
+
+	var (
+		pending = 1
+		hedged  bool
+		last    *hedgeAttempt
+	)
+	for {
+		select {
+		case <-timer.C:
+			if !hedged {
+				hedged = true
+				pending++
+				go send(hedge, 2)
+			}
+			continue
+		case a := <-done:
+			pending--
+			last = a
+		}
+
+		if last.final() {
+			break
+		}
+		if !hedged {
+			// 第一个请求失败时立即发送对冲请求
+			hedged = true
+			pending++
+			go send(hedge, 2)
+		}
+		if pending == 0 {
+			break
+		}
+	}
+
+	if last.result != nil {
+		return last.result
+	}
+	if v != nil && len(last.data) > 0 {
+		if err := json.Unmarshal(last.data, v); err != nil {
+			return NewErrorResult(err.Error())
+		}
+	}
+	return nil
+}
+
+// contextTransport 使用ctx发送请求，ctx取消时请求被中断
+type contextTransport struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+// RoundTrip 实现http.RoundTripper接口
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return next.RoundTrip(req.WithContext(t.ctx))
+}
@@ -0,0 +1,95 @@
+package asapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newVerifyTestServer(delay time.Duration, userID string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		if r.URL.Query().Get("access_token") != "token" {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"user_id":"` + userID + `","client_id":"client","expires_in":3600}`))
+	}))
+}
+
+func TestHedgedVerifyToken(t *testing.T) {
+	slow := newVerifyTestServer(time.Second*5, "slow")
+	defer slow.Close()
+	fast := newVerifyTestServer(0, "fast")
+	defer fast.Close()
+
+	ah := NewAuthorizeHandle(&Config{
+		ASURL:           slow.URL,
+		ServiceIdentify: "TEST",
+		Hedge:           &HedgeConfig{URL: fast.URL, Delay: 10},
+	})
+
+	start := time.Now()
+	info, result := ah.VerifyTokenV2("token")
+	if result != nil {
+		t.Fatal(result)
+	}
+	if info.UserID != "fast" {
+		t.Fatalf("unexpected user: %s", info.UserID)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("hedged request took %v", d)
+	}
+
+	// 授权服务明确的错误响应不等待对冲请求
+	ah = NewAuthorizeHandle(&Config{
+		ASURL:           fast.URL,
+		ServiceIdentify: "TEST",
+		Hedge:           &HedgeConfig{URL: slow.URL, Delay: 10},
+	})
+	start = time.Now()
+	if _, _, result = ah.VerifyToken("bad"); result == nil || result.Code != http.StatusUnauthorized {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("verify took %v", d)
+	}
+}
+
+func TestHedgeKeepsLoserAvailable(t *testing.T) {
+	slow := newVerifyTestServer(time.Second*5, "slow")
+	defer slow.Close()
+	fast := newVerifyTestServer(0, "fast")
+	defer fast.Close()
+
+	ah := NewAuthorizeHandle(&Config{
+		ASURL:           slow.URL,
+		ASURLs:          []string{fast.URL},
+		ServiceIdentify: "TEST",
+		Hedge:           &HedgeConfig{Delay: 10},
+	})
+	eps := ah.tokenHandle().endpoints
+	// 固定从慢节点开始
+	eps.next = 0
+
+	info, result := ah.VerifyTokenV2("token")
+	if result != nil {
+		t.Fatal(result)
+	}
+	if info.UserID != "fast" {
+		t.Fatalf("unexpected user: %s", info.UserID)
+	}
+	// 等待被取消的请求结束
+	ah.Close()
+
+	for _, ep := range eps.list {
+		if !ep.downUntil.IsZero() {
+			t.Fatalf("endpoint %s marked down after hedge", ep.url)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package asapi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/antlinker/go-cache"
+	"github.com/antlinker/sdk/utils"
+)
+
+// runJanitor 按指定间隔清理缓存中过期的数据，替代go-cache内部无法停止的清理协程
+func runJanitor(interval time.Duration, caches ...*cache.Cache) *janitor {
+	j := &janitor{stop: make(chan struct{})}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				for _, c := range caches {
+					c.DeleteExpired()
+				}
+			case <-j.stop:
+				return
+			}
+		}
+	}()
+	return j
+}
+
+// janitor 缓存清理
+type janitor struct {
+	stop chan struct{}
+	once sync.Once
+}
+
+// Stop 停止清理
+func (j *janitor) Stop() {
+	j.once.Do(func() { close(j.stop) })
+}
+
+// closeIdleConnections 关闭连接池中的空闲连接
+func closeIdleConnections(rt http.RoundTripper) {
+	if t, ok := rt.(interface{ CloseIdleConnections() }); ok {
+		t.CloseIdleConnections()
+	}
+}
+
+// Shutdown 停止接受新的请求，等待进行中的请求完成后释放缓存清理协程和空闲连接，
+// ctx结束时不再等待并返回ctx.Err()。关闭后的请求返回"sdk: 已关闭"错误
+func (ah *AuthorizeHandle) Shutdown(ctx context.Context) error {
+	err := ah.inflight.Shutdown(ctx)
+	ah.closeOnce.Do(func() {
+		if ah.release != nil {
+			ah.release()
+		}
+	})
+	return err
+}
+
+// Close 关闭授权处理，等待进行中的请求完成
+func (ah *AuthorizeHandle) Close() error {
+	return ah.Shutdown(context.Background())
+}
+
+// HealthCheck 检查授权服务是否可用，配置了多个节点时有一个节点可用即返回nil
+func (ah *AuthorizeHandle) HealthCheck(ctx context.Context) error {
+	if ah.inflight.Closed() {
+		return utils.ErrClosed
+	}
+	return ah.tokenHandle().endpoints.check(ctx, ah.tokenHandle().roundTripper())
+}
+
+// Shutdown 关闭所有已注册的授权处理，并释放共享的缓存清理协程和空闲连接
+func (r *Registry) Shutdown(ctx context.Context) (err error) {
+	r.Range(func(_ string, ah *AuthorizeHandle) bool {
+		if verr := ah.Shutdown(ctx); verr != nil && err == nil {
+			err = verr
+		}
+		return true
+	})
+	r.lock.RLock()
+	for _, th := range r.tokens {
+		th.endpoints.stopHealthCheck()
+		if th.transport != r.transport {
+			closeIdleConnections(th.roundTripper())
+		}
+	}
+	r.lock.RUnlock()
+	r.janitor.Stop()
+	r.cache.Flush()
+	r.routerCache.Flush()
+	closeIdleConnections(r.transport)
+	return
+}
+
+// Close 关闭注册表，等待进行中的请求完成
+func (r *Registry) Close() error {
+	return r.Shutdown(context.Background())
+}
@@ -0,0 +1,68 @@
+package asapi
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/antlinker/sdk/logging"
+	"github.com/antlinker/sdk/redact"
+)
+
+// SetLogger 设置授权处理使用的日志，为nil时使用logging.Default()，同时应用到获取令牌的请求
+func (ah *AuthorizeHandle) SetLogger(l logging.Logger) {
+	ah.log = l
+	ah.tokenHandle().SetLogger(l)
+}
+
+func (ah *AuthorizeHandle) logger() logging.Logger {
+	return logging.Or(ah.log)
+}
+
+func (ah *AuthorizeHandle) logRequest(router, method string, status int, start time.Time, result *ErrorResult, args []interface{}) {
+	logResult(ah.logger(), router, method, status, start, result, args)
+}
+
+// logResult 请求成功时输出Debug日志，失败时输出Warn日志
+func logResult(l logging.Logger, router, method string, status int, start time.Time, result *ErrorResult, args []interface{}) {
+	fields := append([]interface{}{
+		logging.KeyComponent, "asapi",
+		logging.KeyRouter, router,
+		logging.KeyMethod, method,
+		logging.KeyStatus, status,
+		logging.KeyDuration, time.Since(start),
+	}, args...)
+
+	if result != nil {
+		l.Warn("asapi request failed", append(fields, logging.KeyError, redact.String(result.Error()))...)
+		return
+	}
+	l.Debug("asapi request", fields...)
+}
+
+// uidOf 获取请求参数中的用户ID，支持map和带有UID字段的结构体
+func uidOf(body interface{}) string {
+	switch b := body.(type) {
+	case map[string]interface{}:
+		uid, _ := b["UID"].(string)
+		return uid
+	case jsonBody:
+		return b.uid()
+	case *cachedBody:
+		return b.fields.uid()
+	}
+
+	v := reflect.ValueOf(body)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	if f := v.FieldByName("UID"); f.IsValid() && f.Kind() == reflect.String {
+		return f.String()
+	}
+	return ""
+}
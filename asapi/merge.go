@@ -0,0 +1,324 @@
+package asapi
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// 合并类型
+const (
+	MergeKindUser    = "user" // MergeUser
+	MergeKindTELUser = "tel"  // MergeTELUser
+)
+
+// 合并状态
+const (
+	MergePending    = "pending"    // 已记录合并前的用户信息，尚未完成合并
+	MergeSucceeded  = "succeeded"  // 合并成功
+	MergeFailed     = "failed"     // 合并失败
+	MergeRolledBack = "rolledback" // 已回滚
+)
+
+// MergeSnapshot 合并前的用户信息
+type MergeSnapshot struct {
+	UID        string
+	User       *LoginUserInfo // 不包含登录密码（Password），只保留授权服务重新增加用户所需的默认密码
+	Version    *GetUserVersionResult
+	StaffParam *GetAntStaffParamResult // 获取失败时为nil
+}
+
+// MergeConflict 两个用户不一致的字段
+type MergeConflict struct {
+	Field  string
+	Values [2]string // 与MergePlan.Users的顺序一致
+}
+
+// MergePlan 合并预览
+type MergePlan struct {
+	Kind        string
+	User        *AuthorizeMergeUserRequest    `json:",omitempty"` // Kind为MergeKindUser时的请求参数
+	TELUser     *AuthorizeMergeTELUserRequest `json:",omitempty"` // Kind为MergeKindTELUser时的请求参数
+	Users       [2]*MergeSnapshot             // MergeUser为UID和TUID，MergeTELUser为MUID和CUID
+	Conflicts   []*MergeConflict              // 两个用户不一致的字段
+	PreviewTime time.Time
+}
+
+// MergeAudit 合并审计记录，保存了回滚所需的用户信息（包括手机号、身份证号和默认密码，不包括登录密码），注意妥善保管
+type MergeAudit struct {
+	ID     string
+	Status string
+	Plan   *MergePlan
+	Error  *ErrorResult `json:",omitempty"` // 合并或回滚失败时的错误结果
+	Time   time.Time    // 记录时间
+}
+
+// MergeAuditor 合并审计记录的存储
+type MergeAuditor interface {
+	// Record 保存审计记录，同一ID的记录可能保存多次，以最后一次为准
+	Record(audit *MergeAudit) error
+}
+
+// NewFileMergeAuditor 创建基于文件的审计记录，每行记录一条JSON格式的审计记录
+func NewFileMergeAuditor(name string) (*FileMergeAuditor, error) {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileMergeAuditor{file: f}, nil
+}
+
+// FileMergeAuditor 基于文件的审计记录
+type FileMergeAuditor struct {
+	lock sync.Mutex
+	file *os.File
+}
+
+// Record 保存审计记录
+func (a *FileMergeAuditor) Record(audit *MergeAudit) error {
+	buf, err := json.Marshal(audit)
+	if err != nil {
+		return err
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	_, err = a.file.Write(append(buf, '\n'))
+	return err
+}
+
+// Close 关闭审计记录文件
+func (a *FileMergeAuditor) Close() error {
+	return a.file.Close()
+}
+
+// ReadMergeAudits 读取审计记录文件，同一ID的记录只保留最后一次，按首次记录的顺序返回
+func ReadMergeAudits(name string) ([]*MergeAudit, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		audits []*MergeAudit
+		index  = make(map[string]int)
+	)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var audit MergeAudit
+		if err := json.Unmarshal(scanner.Bytes(), &audit); err != nil {
+			return nil, err
+		}
+		if i, ok := index[audit.ID]; ok {
+			audits[i] = &audit
+			continue
+		}
+		index[audit.ID] = len(audits)
+		audits = append(audits, &audit)
+	}
+	return audits, scanner.Err()
+}
+
+// PreviewMergeUser 获取两个用户的信息并生成合并预览，不修改数据
+func (ah *AuthorizeHandle) PreviewMergeUser(req *AuthorizeMergeUserRequest) (*MergePlan, *ErrorResult) {
+	if req.TUID == "" {
+		return nil, NewErrorResult("合并预览需要指定TUID", http.StatusBadRequest)
+	}
+	plan := &MergePlan{Kind: MergeKindUser, User: req}
+	if result := ah.previewMerge(plan, req.UID, req.TUID); result != nil {
+		return nil, result
+	}
+	return plan, nil
+}
+
+// PreviewMergeTELUser 获取两个用户的信息并生成合并手机号用户的预览，不修改数据
+func (ah *AuthorizeHandle) PreviewMergeTELUser(req *AuthorizeMergeTELUserRequest) (*MergePlan, *ErrorResult) {
+	plan := &MergePlan{Kind: MergeKindTELUser, TELUser: req}
+	if result := ah.previewMerge(plan, req.MUID, req.CUID); result != nil {
+		return nil, result
+	}
+	return plan, nil
+}
+
+func (ah *AuthorizeHandle) previewMerge(plan *MergePlan, uids ...string) *ErrorResult {
+	for i, uid := range uids {
+		if uid == "" || (i > 0 && uid == uids[0]) {
+			return NewErrorResult("合并的用户ID为空或相同", http.StatusBadRequest)
+		}
+		snap, result := ah.mergeSnapshot(uid)
+		if result != nil {
+			return result
+		}
+		plan.Users[i] = snap
+	}
+	plan.Conflicts = mergeConflicts(plan.Users)
+	plan.PreviewTime = time.Now()
+	return nil
+}
+
+// mergeSnapshot 获取用户信息、版本和学工参数
+func (ah *AuthorizeHandle) mergeSnapshot(uid string) (snap *MergeSnapshot, result *ErrorResult) {
+	snap = &MergeSnapshot{UID: uid}
+	if snap.User, result = ah.GetUser(uid); result != nil {
+		return
+	}
+	// 审计记录会保存到文件中，不保存登录密码
+	snap.User.Password = ""
+	if snap.Version, result = ah.GetUserVersion(uid); result != nil {
+		return
+	}
+	// 学工参数只用于比对，没有学工信息的用户也可以合并
+	snap.StaffParam, _ = ah.GetAntStaffParam(uid)
+	return
+}
+
+// mergeConflicts 比对两个用户都有值但不一致的字段
+func mergeConflicts(users [2]*MergeSnapshot) (conflicts []*MergeConflict) {
+	fields := func(s *MergeSnapshot) map[string]string {
+		m := map[string]string{
+			"MobilePhone": s.User.MobilePhone,
+			"UserCode":    s.User.UserCode,
+			"IDCard":      s.User.IDCard,
+			"University":  s.User.University,
+			"UserType":    string(s.User.UserType),
+		}
+		if p := s.StaffParam; p != nil {
+			m["StaffParam.University"] = p.University
+			m["StaffParam.IntelUserCode"] = p.IntelUserCode
+		}
+		return m
+	}
+
+	a, b := fields(users[0]), fields(users[1])
+	for _, name := range []string{"MobilePhone", "UserCode", "IDCard", "University", "UserType", "StaffParam.University", "StaffParam.IntelUserCode"} {
+		if a[name] != "" && b[name] != "" && a[name] != b[name] {
+			conflicts = append(conflicts, &MergeConflict{Field: name, Values: [2]string{a[name], b[name]}})
+		}
+	}
+	return
+}
+
+// ExecuteMerge 按预览执行合并
+// 合并前重新获取用户版本，与预览时不一致时返回409错误；
+// 合并前后都会保存审计记录，合并前的记录保存失败时不执行合并
+func (ah *AuthorizeHandle) ExecuteMerge(plan *MergePlan, auditor MergeAuditor) (audit *MergeAudit, result *ErrorResult) {
+	for _, snap := range plan.Users {
+		if snap == nil {
+			return nil, NewErrorResult("无效的合并预览", http.StatusBadRequest)
+		}
+		version, vresult := ah.GetUserVersion(snap.UID)
+		if vresult != nil {
+			return nil, vresult
+		}
+		if version.Version != snap.Version.Version {
+			return nil, NewErrorResult("用户信息已变更，请重新预览："+snap.UID, http.StatusConflict)
+		}
+	}
+
+	audit = &MergeAudit{ID: newMergeID(), Status: MergePending, Plan: plan}
+	if result = recordMerge(auditor, audit); result != nil {
+		return nil, result
+	}
+
+	switch plan.Kind {
+	case MergeKindUser:
+		result = ah.MergeUser(plan.User)
+	case MergeKindTELUser:
+		result = ah.MergeTELUser(plan.TELUser)
+	default:
+		result = NewErrorResult("未知的合并类型："+plan.Kind, http.StatusBadRequest)
+	}
+
+	audit.Status, audit.Error = MergeSucceeded, result
+	if result != nil {
+		audit.Status = MergeFailed
+	}
+	if aresult := recordMerge(auditor, audit); result == nil {
+		result = aresult
+	}
+	return
+}
+
+// RollbackMerge 根据审计记录恢复合并前的用户信息
+// 仍然存在的用户通过EditUser恢复，已被删除（GetUser返回用户不存在的错误）的用户通过AddUser重新增加，
+// 重新增加的用户没有原来的登录密码，需要使用默认密码登录后修改；
+// 只能回滚合并成功的记录，学工参数、版本等无法通过接口恢复的信息不会恢复。auditor不为nil时保存回滚结果
+func (ah *AuthorizeHandle) RollbackMerge(audit *MergeAudit, auditor MergeAuditor) (result *ErrorResult) {
+	if audit.Status != MergeSucceeded {
+		return NewErrorResult("只能回滚合并成功的记录，当前状态："+audit.Status, http.StatusConflict)
+	}
+	if audit.Plan == nil {
+		return NewErrorResult("审计记录中没有合并前的用户信息", http.StatusBadRequest)
+	}
+
+	for _, snap := range audit.Plan.Users {
+		if snap == nil || snap.User == nil {
+			continue
+		}
+		if result = ah.restoreUser(snap); result != nil {
+			break
+		}
+	}
+
+	if result == nil {
+		audit.Status, audit.Error = MergeRolledBack, nil
+	} else {
+		audit.Error = result
+	}
+	if auditor != nil {
+		if aresult := recordMerge(auditor, audit); result == nil {
+			result = aresult
+		}
+	}
+	return
+}
+
+func (ah *AuthorizeHandle) restoreUser(snap *MergeSnapshot) *ErrorResult {
+	u := snap.User
+	_, result := ah.GetUser(snap.UID)
+	switch {
+	case result == nil:
+		return ah.EditUser(snap.UID, &AuthorizeEditUserRequest{
+			MobilePhone: u.MobilePhone,
+			UserCode:    u.UserCode,
+			IDCard:      u.IDCard,
+			University:  u.University,
+		})
+	case result.IsUserNotFound():
+		// 只恢复默认密码，不检查密码策略
+		return ah.addUser(snap.UID, &AuthorizeAddUserRequest{
+			MobilePhone:     u.MobilePhone,
+			UserCode:        u.UserCode,
+			IDCard:          u.IDCard,
+			DefaultPassword: u.DefaultPassword,
+			University:      u.University,
+		}, false)
+	}
+	return result
+}
+
+func recordMerge(auditor MergeAuditor, audit *MergeAudit) *ErrorResult {
+	if auditor == nil {
+		return NewErrorResult("没有指定合并审计记录", http.StatusBadRequest)
+	}
+	audit.Time = time.Now()
+	if err := auditor.Record(audit); err != nil {
+		return NewErrorResult("保存合并审计记录失败：" + err.Error())
+	}
+	return nil
+}
+
+func newMergeID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return time.Now().Format("20060102150405") + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,150 @@
+package asapi_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/asapi/fakeas"
+	"github.com/antlinker/sdk/validation"
+)
+
+// fakeMergeServer 模拟合并用户：UID的信息合并到TUID后删除UID
+type fakeMergeServer struct {
+	lock     sync.Mutex
+	users    map[string]*asapi.LoginUserInfo
+	versions map[string]int
+}
+
+func (s *fakeMergeServer) server() *fakeas.Server {
+	fs := &fakeas.Server{}
+	fs.GetUser = func(req map[string]interface{}) (*asapi.LoginUserInfo, *asapi.ErrorResult) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		if u, ok := s.users[req["UID"].(string)]; ok {
+			cp := *u
+			return &cp, nil
+		}
+		return nil, asapi.NewErrorResult(`{"code":11,"message":"未知的用户"}`, http.StatusBadRequest)
+	}
+	fs.GetUserVersion = func(req map[string]interface{}) (*asapi.GetUserVersionResult, *asapi.ErrorResult) {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		return &asapi.GetUserVersionResult{Version: s.versions[req["UID"].(string)]}, nil
+	}
+	fs.MergeUser = func(req *asapi.AuthorizeMergeUserRequest) *asapi.ErrorResult {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		src, dst := s.users[req.UID], s.users[req.TUID]
+		dst.MobilePhone = src.MobilePhone
+		delete(s.users, req.UID)
+		s.versions[req.TUID]++
+		return nil
+	}
+	fs.AddUser = func(req map[string]interface{}) *asapi.ErrorResult {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.users[req["UID"].(string)] = &asapi.LoginUserInfo{
+			MobilePhone:     req["MobilePhone"].(string),
+			UserCode:        req["UserCode"].(string),
+			Password:        req["Password"].(string),
+			DefaultPassword: req["DefaultPassword"].(string),
+			University:      req["University"].(string),
+		}
+		return nil
+	}
+	fs.EditUser = func(req map[string]interface{}) *asapi.ErrorResult {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		u := s.users[req["UID"].(string)]
+		u.MobilePhone, u.UserCode, u.University = req["MobilePhone"].(string), req["UserCode"].(string), req["University"].(string)
+		return nil
+	}
+	return fs
+}
+
+func TestMergeWorkflow(t *testing.T) {
+	fake := &fakeMergeServer{
+		users: map[string]*asapi.LoginUserInfo{
+			"u1": {MobilePhone: "13800000001", UserCode: "001", University: "A", Password: "p1", DefaultPassword: "d1"},
+			"u2": {MobilePhone: "13800000002", UserCode: "002", University: "A"},
+		},
+		versions: map[string]int{"u1": 1, "u2": 1},
+	}
+	// 回滚恢复用户时不检查密码策略
+	ah := newFakeHandle(t, fake.server(), &asapi.Config{PasswordPolicy: &validation.PasswordPolicy{}})
+
+	name := filepath.Join(t.TempDir(), "merge.audit")
+	auditor, err := asapi.NewFileMergeAuditor(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer auditor.Close()
+
+	req := &asapi.AuthorizeMergeUserRequest{UID: "u1", TUID: "u2"}
+	plan, result := ah.PreviewMergeUser(req)
+	if result != nil {
+		t.Fatal(result)
+	}
+	if len(plan.Conflicts) != 2 || plan.Conflicts[0].Field != "MobilePhone" || plan.Conflicts[0].Values != [2]string{"13800000001", "13800000002"} {
+		t.Fatalf("unexpected conflicts: %+v", plan.Conflicts)
+	}
+
+	// 预览后用户被修改
+	fake.versions["u2"]++
+	if _, result = ah.ExecuteMerge(plan, auditor); result == nil || result.Code != http.StatusConflict {
+		t.Fatalf("expected conflict, got %v", result)
+	}
+	if plan, result = ah.PreviewMergeUser(req); result != nil {
+		t.Fatal(result)
+	}
+
+	audit, result := ah.ExecuteMerge(plan, auditor)
+	if result != nil {
+		t.Fatal(result)
+	}
+	if audit.Status != asapi.MergeSucceeded || fake.users["u1"] != nil || fake.users["u2"].MobilePhone != "13800000001" {
+		t.Fatalf("unexpected merge: %+v, %+v", audit, fake.users)
+	}
+
+	// 审计记录中不保存登录密码
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(buf), `"p1"`) {
+		t.Fatalf("audit contains the password: %s", buf)
+	}
+
+	// 从文件中读取审计记录并回滚
+	audits, err := asapi.ReadMergeAudits(name)
+	if err != nil || len(audits) != 1 || audits[0].ID != audit.ID || audits[0].Status != asapi.MergeSucceeded {
+		t.Fatalf("unexpected audits: %v, %v", audits, err)
+	}
+	if result = ah.RollbackMerge(audits[0], auditor); result != nil {
+		t.Fatal(result)
+	}
+	if u := fake.users["u1"]; u == nil || u.MobilePhone != "13800000001" || u.Password != "" || u.DefaultPassword != "d1" {
+		t.Fatalf("u1 not restored: %+v", u)
+	}
+	if u := fake.users["u2"]; u.MobilePhone != "13800000002" {
+		t.Fatalf("u2 not restored: %+v", u)
+	}
+
+	audits, _ = asapi.ReadMergeAudits(name)
+	if len(audits) != 1 || audits[0].Status != asapi.MergeRolledBack {
+		t.Fatalf("unexpected audits: %+v", audits)
+	}
+
+	// 已回滚、未完成或失败的记录不能回滚
+	for _, status := range []string{asapi.MergeRolledBack, asapi.MergePending, asapi.MergeFailed} {
+		audits[0].Status = status
+		if result = ah.RollbackMerge(audits[0], auditor); result == nil || result.Code != http.StatusConflict {
+			t.Fatalf("%s: expected conflict, got %v", status, result)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package asapi
+
+import (
+	"github.com/antlinker/sdk/validation"
+)
+
+// checkPassword 配置了PasswordPolicy时检查密码强度，密码为空时不检查
+func (ah *AuthorizeHandle) checkPassword(password string, user *validation.PasswordUser) *ErrorResult {
+	if ah.cfg.PasswordPolicy == nil || password == "" {
+		return nil
+	}
+	if err := ah.cfg.PasswordPolicy.Check(password, user); err != nil {
+		return NewErrorResult(err.Error())
+	}
+	return nil
+}
+
+// GenerateStaffPasswords 为未指定密码的学工用户生成符合策略的随机密码（policy为nil时使用默认策略），
+// 用于批量增加用户前设置默认密码，返回生成了密码的用户ID
+func GenerateStaffPasswords(reqs []*AddStaffUserRequest, policy *validation.PasswordPolicy) (uids []string, err error) {
+	for _, req := range reqs {
+		if req == nil || req.Password != "" {
+			continue
+		}
+		if req.Password, err = policy.Generate(); err != nil {
+			return
+		}
+		uids = append(uids, req.UID)
+	}
+	return
+}
@@ -0,0 +1,61 @@
+package asapi_test
+
+import (
+	"testing"
+
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/asapi/fakeas"
+	"github.com/antlinker/sdk/validation"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	var (
+		modified []string
+		services []string
+	)
+	s := &fakeas.Server{}
+	s.GetUser = func(req map[string]interface{}) (*asapi.LoginUserInfo, *asapi.ErrorResult) {
+		services = append(services, req["ServiceIdentify"].(string))
+		return &asapi.LoginUserInfo{UserCode: "Zs2020abc", IDCard: "11010519491231002X"}, nil
+	}
+	s.ModifyPwd = func(req map[string]interface{}) *asapi.ErrorResult {
+		modified = append(modified, req["Password"].(string))
+		return nil
+	}
+	ah := newFakeHandle(t, s, &asapi.Config{PasswordPolicy: &validation.PasswordPolicy{}})
+
+	// 长度、字符类别不符合时不请求用户信息
+	if result := ah.ModifyPwd("u1", "123456"); result == nil || len(services) != 0 {
+		t.Fatalf("expected local error, got %v, %v", result, services)
+	}
+	if result := ah.ModifyPwd("u1", "zs2020ABC"); result == nil {
+		t.Fatal("expected error")
+	}
+	if result := ah.ModifyPwd("u1", "Strong!Pass9", "OTHER"); result != nil {
+		t.Fatal(result)
+	}
+	if len(modified) != 1 || modified[0] != "Strong!Pass9" {
+		t.Fatalf("unexpected requests: %v", modified)
+	}
+	if len(services) != 2 || services[0] != "TEST" || services[1] != "OTHER" {
+		t.Fatalf("unexpected getuser services: %v", services)
+	}
+
+	if result := ah.AddStaffUser(&asapi.AddStaffUserRequest{UID: "u2", UserCode: "u2", Password: "password"}); result == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestGenerateStaffPasswords(t *testing.T) {
+	reqs := []*asapi.AddStaffUserRequest{{UID: "u1"}, {UID: "u2", Password: "Keep!Me123"}}
+	uids, err := asapi.GenerateStaffPasswords(reqs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uids) != 1 || uids[0] != "u1" || reqs[1].Password != "Keep!Me123" {
+		t.Fatalf("unexpected result: %v, %+v", uids, reqs)
+	}
+	if err := (*validation.PasswordPolicy)(nil).Check(reqs[0].Password, nil); err != nil {
+		t.Fatal(err)
+	}
+}
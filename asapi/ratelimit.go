@@ -0,0 +1,145 @@
+package asapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrRateLimited 超出客户端请求频率限制
+var ErrRateLimited = errors.New("asapi: 超出客户端请求频率限制")
+
+// RateLimit 请求频率
+type RateLimit struct {
+	Rate  float64 // 每秒允许的请求数，0表示不限制
+	Burst int     // 允许的突发请求数，默认为Rate向上取整
+}
+
+// RateLimitConfig 客户端请求频率限制（令牌桶）
+type RateLimitConfig struct {
+	RateLimit                      // 所有请求的频率限制
+	Routers   map[string]RateLimit // 按路由的频率限制，如 /api/authorize/addstaffuser
+	FailFast  bool                 // 超出限制时立即返回ErrRateLimited，默认等待直到允许请求或ctx结束
+}
+
+// Validate 检查频率限制参数
+func (c *RateLimitConfig) Validate() error {
+	if c.Rate < 0 || c.Burst < 0 {
+		return errors.New("asapi: 请求频率(RateLimit)不能小于0")
+	}
+	for _, v := range c.Routers {
+		if v.Rate < 0 || v.Burst < 0 {
+			return errors.New("asapi: 请求频率(RateLimit)不能小于0")
+		}
+	}
+	return nil
+}
+
+// SetRateLimit 设置客户端请求频率限制，cfg为nil时不限制。应在发起请求前设置
+func (ah *AuthorizeHandle) SetRateLimit(cfg *RateLimitConfig) {
+	ah.limiter = newLimiter(cfg)
+}
+
+func newLimiter(cfg *RateLimitConfig) *limiter {
+	if cfg == nil {
+		return nil
+	}
+	l := &limiter{
+		cfg:     cfg,
+		global:  newRateLimiter(cfg.RateLimit),
+		routers: make(map[string]*rate.Limiter, len(cfg.Routers)),
+	}
+	for router, v := range cfg.Routers {
+		if rl := newRateLimiter(v); rl != nil {
+			l.routers[router] = rl
+		}
+	}
+	return l
+}
+
+func newRateLimiter(v RateLimit) *rate.Limiter {
+	if v.Rate <= 0 {
+		return nil
+	}
+	burst := v.Burst
+	if burst == 0 {
+		burst = int(v.Rate)
+		if float64(burst) < v.Rate {
+			burst++
+		}
+	}
+	return rate.NewLimiter(rate.Limit(v.Rate), burst)
+}
+
+// limiter 授权服务请求频率限制
+type limiter struct {
+	cfg     *RateLimitConfig
+	global  *rate.Limiter
+	routers map[string]*rate.Limiter
+	lock    sync.Mutex
+	until   time.Time // 授权服务通过Retry-After要求暂停请求的截止时间
+}
+
+// wait 等待直到允许请求router，FailFast时不等待
+func (l *limiter) wait(ctx context.Context, router string) error {
+	if l == nil {
+		return nil
+	}
+
+	l.lock.Lock()
+	until := l.until
+	l.lock.Unlock()
+	if d := time.Until(until); d > 0 {
+		if l.cfg.FailFast {
+			return ErrRateLimited
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+
+	for _, rl := range []*rate.Limiter{l.routers[router], l.global} {
+		if rl == nil {
+			continue
+		}
+		if l.cfg.FailFast {
+			if !rl.Allow() {
+				return ErrRateLimited
+			}
+		} else if err := rl.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retryAfter 根据429响应的Retry-After（秒数或HTTP时间）暂停请求
+func (l *limiter) retryAfter(v string) {
+	if l == nil || v == "" {
+		return
+	}
+
+	var until time.Time
+	if sec, err := strconv.Atoi(v); err == nil {
+		until = time.Now().Add(time.Duration(sec) * time.Second)
+	} else if t, err := http.ParseTime(v); err == nil {
+		until = t
+	} else {
+		return
+	}
+
+	l.lock.Lock()
+	if until.After(l.until) {
+		l.until = until
+	}
+	l.lock.Unlock()
+}
@@ -0,0 +1,89 @@
+package asapi_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/asapi/fakeas"
+)
+
+func TestRateLimitFailFast(t *testing.T) {
+	var calls int32
+	s := &fakeas.Server{}
+	s.AddStaffUser = func(*asapi.AddStaffUserRequest) *asapi.ErrorResult {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}
+	ah := newFakeHandle(t, s, &asapi.Config{
+		RateLimit: &asapi.RateLimitConfig{
+			Routers:  map[string]asapi.RateLimit{"/api/authorize/addstaffuser": {Rate: 1, Burst: 2}},
+			FailFast: true,
+		},
+	})
+
+	var limited int
+	for i := 0; i < 5; i++ {
+		if result := ah.AddStaffUser(&asapi.AddStaffUserRequest{UID: "u1"}); result != nil {
+			if result.Code != http.StatusTooManyRequests {
+				t.Fatalf("unexpected result: %v", result)
+			}
+			limited++
+		}
+	}
+	if calls != 2 || limited != 3 {
+		t.Fatalf("unexpected calls: %d, limited: %d", calls, limited)
+	}
+}
+
+func TestRateLimitWait(t *testing.T) {
+	s := &fakeas.Server{}
+	s.AddStaffUser = func(*asapi.AddStaffUserRequest) *asapi.ErrorResult { return nil }
+	ah := newFakeHandle(t, s, &asapi.Config{
+		RateLimit: &asapi.RateLimitConfig{RateLimit: asapi.RateLimit{Rate: 1, Burst: 1}},
+	})
+	if result := ah.AddStaffUser(&asapi.AddStaffUserRequest{UID: "u1"}); result != nil {
+		t.Fatal(result)
+	}
+
+	// 等待超过ctx的截止时间时返回错误
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	if result := ah.WithContext(ctx).AddStaffUser(&asapi.AddStaffUserRequest{UID: "u1"}); result == nil {
+		t.Fatal("expected context error")
+	}
+}
+
+func TestRateLimitRetryAfter(t *testing.T) {
+	var calls int32
+	s := &fakeas.Server{}
+	s.AddStaffUser = func(*asapi.AddStaffUserRequest) *asapi.ErrorResult { return nil }
+	h := s.Handler()
+	ah := newFakeHandleFor(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/authorize/addstaffuser" && atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}), &asapi.Config{RateLimit: &asapi.RateLimitConfig{FailFast: true}})
+
+	if result := ah.AddStaffUser(&asapi.AddStaffUserRequest{UID: "u1"}); result == nil || result.Code != http.StatusTooManyRequests {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	// Retry-After期间的请求不发送到授权服务
+	if result := ah.AddStaffUser(&asapi.AddStaffUserRequest{UID: "u1"}); result == nil || result.Code != http.StatusTooManyRequests {
+		t.Fatalf("unexpected result: %v", result)
+	}
+	if calls != 1 {
+		t.Fatalf("unexpected calls: %d", calls)
+	}
+
+	ah.SetRateLimit(&asapi.RateLimitConfig{})
+	if result := ah.AddStaffUser(&asapi.AddStaffUserRequest{UID: "u1"}); result != nil {
+		t.Fatalf("unexpected result: %v", result)
+	}
+}
@@ -0,0 +1,253 @@
+package asapi
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/antlinker/go-cache"
+)
+
+type serviceIdentifyKey struct{}
+
+// WithServiceIdentify 将服务标识放入上下文，用于从注册表中选择授权处理
+func WithServiceIdentify(ctx context.Context, identify string) context.Context {
+	return context.WithValue(ctx, serviceIdentifyKey{}, identify)
+}
+
+// ServiceIdentifyFromContext 从上下文中获取服务标识
+func ServiceIdentifyFromContext(ctx context.Context) (identify string, ok bool) {
+	identify, ok = ctx.Value(serviceIdentifyKey{}).(string)
+	return
+}
+
+// RegistryConfig 注册表配置参数
+type RegistryConfig struct {
+	MaxConns        int // 共享连接池中每个主机的最大连接数，0表示不保持连接
+	CacheGCInterval int // 共享缓存gc间隔(单位秒)，默认300
+}
+
+// NewRegistry 创建授权处理注册表
+func NewRegistry(cfg *RegistryConfig) *Registry {
+	if cfg == nil {
+		cfg = new(RegistryConfig)
+	}
+	if cfg.CacheGCInterval <= 0 {
+		cfg.CacheGCInterval = 300
+	}
+
+	r := &Registry{
+		cfg:         cfg,
+		transport:   newTransport(cfg.MaxConns, nil),
+		cache:       cache.New(0, 0),
+		routerCache: cache.New(0, 0),
+		handles:     make(map[string]*AuthorizeHandle),
+		clients:     make(map[string]*AuthorizeHandle),
+		tokens:      make(map[string]*TokenHandle),
+	}
+	r.janitor = runJanitor(time.Second*time.Duration(cfg.CacheGCInterval), r.cache, r.routerCache)
+	return r
+}
+
+// Registry 授权处理注册表
+// 按服务标识和客户端ID管理多个授权处理，所有授权处理共享连接池和缓存，
+// 使用相同授权服务、客户端ID和秘钥的授权处理共享访问令牌，共享令牌的授权处理需要使用相同的节点和TLS配置
+type Registry struct {
+	cfg         *RegistryConfig
+	transport   http.RoundTripper
+	cache       *cache.Cache
+	routerCache *cache.Cache
+	janitor     *janitor
+	lock        sync.RWMutex
+	handles     map[string]*AuthorizeHandle // 以服务标识为键
+	clients     map[string]*AuthorizeHandle // 以客户端ID为键
+	tokens      map[string]*TokenHandle     // 以授权服务URL、客户端ID和秘钥为键
+	def         *AuthorizeHandle
+}
+
+// Register 注册授权处理，第一个注册的授权处理作为默认的授权处理
+func (r *Registry) Register(cfg *Config) (*AuthorizeHandle, error) {
+	if cfg.ServiceIdentify == "" {
+		return nil, fmt.Errorf("asapi: 未指定服务标识(ServiceIdentify)")
+	}
+	if cfg.IsEnabledCache && cfg.CacheGCInterval == 0 {
+		cfg.CacheGCInterval = r.cfg.CacheGCInterval
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.handles[cfg.ServiceIdentify]; ok {
+		return nil, fmt.Errorf("asapi: 服务标识%s已注册", cfg.ServiceIdentify)
+	}
+
+	th, err := r.tokenHandle(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var c, rc *cache.Cache
+	if cfg.IsEnabledCache {
+		c, rc = r.cache, r.routerCache
+	}
+	ah := newAuthorizeHandle(cfg, th, c, rc)
+	ah.tokens.rebind = func(old *TokenHandle, clientID, clientSecret string) *TokenHandle {
+		return r.rebindToken(cfg.ServiceIdentify, old, clientID, clientSecret)
+	}
+
+	r.handles[cfg.ServiceIdentify] = ah
+	if _, ok := r.clients[cfg.ClientID]; !ok && cfg.ClientID != "" {
+		r.clients[cfg.ClientID] = ah
+	}
+	if r.def == nil {
+		r.def = ah
+	}
+	return ah, nil
+}
+
+// tokenKey 令牌处理的键，由授权服务URL、客户端ID和秘钥组成
+func tokenKey(cfg *Config) string {
+	secret := md5.Sum([]byte(cfg.ClientSecret))
+	return strings.Join(cfg.urls(), ",") + "|" + cfg.ClientID + "|" + hex.EncodeToString(secret[:])
+}
+
+// tokenHandle 获取授权服务、客户端ID和秘钥都相同的令牌处理，不存在时创建，调用时需要持有r.lock；
+// 已存在的令牌处理的负载均衡、健康检查或TLS配置与cfg不一致时返回错误
+func (r *Registry) tokenHandle(cfg *Config) (*TokenHandle, error) {
+	key := tokenKey(cfg)
+	if th, ok := r.tokens[key]; ok {
+		if !sameTokenConfig(th.cfg, cfg) {
+			return nil, fmt.Errorf("asapi: 服务标识%s与已注册的服务使用相同的授权服务和客户端，但负载均衡、健康检查或TLS配置不一致", cfg.ServiceIdentify)
+		}
+		return th, nil
+	}
+	return r.newTokenHandle(key, cfg), nil
+}
+
+// newTokenHandle 使用cfg的副本创建令牌处理，以免更新凭据时修改服务自己的配置
+func (r *Registry) newTokenHandle(key string, cfg *Config) *TokenHandle {
+	tcfg := *cfg
+	th := &TokenHandle{
+		cfg:       &tcfg,
+		transport: r.transport,
+		endpoints: newEndpoints(&tcfg),
+	}
+	if cfg.TLS != nil {
+		// 使用TLS配置的授权处理不能共享连接池
+		th.transport = newTransport(r.cfg.MaxConns, cfg.TLS)
+	}
+	th.endpoints.startHealthCheck(time.Duration(cfg.HealthCheckInterval)*time.Second, th.roundTripper)
+	r.tokens[key] = th
+	return th
+}
+
+// rebindToken 服务更新凭据后切换到新凭据对应的令牌处理，
+// 新凭据对应的令牌处理配置不一致时为该服务单独创建，新创建的令牌处理沿用原来的连接池和日志
+func (r *Registry) rebindToken(identify string, old *TokenHandle, clientID, clientSecret string) *TokenHandle {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cfg := *old.cfg
+	cfg.ClientID, cfg.ClientSecret, cfg.ServiceIdentify = clientID, clientSecret, identify
+	key := tokenKey(&cfg)
+	for _, key := range []string{key, key + "|" + identify} {
+		if th, ok := r.tokens[key]; ok {
+			if sameTokenConfig(th.cfg, &cfg) {
+				return th
+			}
+			continue
+		}
+		th := r.newTokenHandle(key, &cfg)
+		th.SetTransport(old.roundTripper())
+		th.SetLogger(old.logger())
+		return th
+	}
+	return old
+}
+
+// sameTokenConfig 检查两个配置能否共享令牌处理
+func sameTokenConfig(a, b *Config) bool {
+	return a.Balance == b.Balance &&
+		a.HealthCheckInterval == b.HealthCheckInterval &&
+		a.FailTimeout == b.FailTimeout &&
+		reflect.DeepEqual(a.TLS, b.TLS)
+}
+
+// SetDefault 设置默认的授权处理，上下文中没有服务标识时使用
+func (r *Registry) SetDefault(identify string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	ah, ok := r.handles[identify]
+	if !ok {
+		return fmt.Errorf("asapi: 服务标识%s未注册", identify)
+	}
+	r.def = ah
+	return nil
+}
+
+// Get 根据服务标识获取授权处理
+func (r *Registry) Get(identify string) (ah *AuthorizeHandle, ok bool) {
+	r.lock.RLock()
+	ah, ok = r.handles[identify]
+	r.lock.RUnlock()
+	return
+}
+
+// GetByClientID 根据客户端ID获取授权处理，多个服务标识使用同一客户端ID时返回第一个注册的
+func (r *Registry) GetByClientID(clientID string) (ah *AuthorizeHandle, ok bool) {
+	r.lock.RLock()
+	ah, ok = r.clients[clientID]
+	r.lock.RUnlock()
+	return
+}
+
+// FromContext 根据上下文中的服务标识获取授权处理，上下文中没有服务标识时返回默认的授权处理，
+// 返回的授权处理已绑定ctx（见WithContext）
+func (r *Registry) FromContext(ctx context.Context) (*AuthorizeHandle, error) {
+	if identify, ok := ServiceIdentifyFromContext(ctx); ok {
+		if ah, ok := r.Get(identify); ok {
+			return ah.WithContext(ctx), nil
+		}
+		return nil, fmt.Errorf("asapi: 服务标识%s未注册", identify)
+	}
+
+	r.lock.RLock()
+	ah := r.def
+	r.lock.RUnlock()
+	if ah == nil {
+		return nil, fmt.Errorf("asapi: 没有注册的授权处理")
+	}
+	return ah.WithContext(ctx), nil
+}
+
+// Handler 返回一个HTTP中间件，使用identify从请求中获取服务标识并放入请求的上下文
+func (r *Registry) Handler(identify func(*http.Request) string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if v := identify(req); v != "" {
+			req = req.WithContext(WithServiceIdentify(req.Context(), v))
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// Range 遍历已注册的授权处理，f返回false时停止遍历
+func (r *Registry) Range(f func(identify string, ah *AuthorizeHandle) bool) {
+	r.lock.RLock()
+	handles := make(map[string]*AuthorizeHandle, len(r.handles))
+	for k, v := range r.handles {
+		handles[k] = v
+	}
+	r.lock.RUnlock()
+
+	for k, v := range handles {
+		if !f(k, v) {
+			return
+		}
+	}
+}
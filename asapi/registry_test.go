@@ -0,0 +1,134 @@
+package asapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	var tokens int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tokens, 1)
+		w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+	})
+	mux.HandleFunc("/oauth2/verify/v2", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"user_id":"u1","service_code":"` + r.FormValue("service") + `","expires_in":3600}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	reg := NewRegistry(&RegistryConfig{MaxConns: 2, CacheGCInterval: 60})
+	for _, identify := range []string{"A", "B"} {
+		_, err := reg.Register(&Config{
+			ASURL:           srv.URL,
+			ClientID:        "client",
+			ClientSecret:    "secret",
+			ServiceIdentify: identify,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := reg.Register(&Config{ServiceIdentify: "A"}); err == nil {
+		t.Fatal("expected duplicate service identify error")
+	}
+
+	ah, err := reg.FromContext(context.Background())
+	if err != nil || ah.GetConfig().ServiceIdentify != "A" {
+		t.Fatalf("unexpected default handle: %v", err)
+	}
+
+	ah, err = reg.FromContext(WithServiceIdentify(context.Background(), "B"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, result := ah.VerifyTokenV2("token")
+	if result != nil {
+		t.Fatal(result)
+	}
+	if info.ServiceCode != "B" {
+		t.Fatalf("unexpected service code: %s", info.ServiceCode)
+	}
+
+	if _, err := reg.FromContext(WithServiceIdentify(context.Background(), "C")); err == nil {
+		t.Fatal("expected unregistered service identify error")
+	}
+
+	// 相同客户端的授权处理共享访问令牌
+	for _, identify := range []string{"A", "B"} {
+		ah, _ := reg.Get(identify)
+		if _, result := ah.GetToken(); result != nil {
+			t.Fatal(result)
+		}
+	}
+	if tokens != 1 {
+		t.Fatalf("unexpected token requests: %d", tokens)
+	}
+}
+
+func TestRegistryTenants(t *testing.T) {
+	reg := NewRegistry(nil)
+	defer reg.Close()
+
+	register := func(identify, secret string, tls *TLSConfig) (*AuthorizeHandle, error) {
+		return reg.Register(&Config{
+			ASURL:           "http://as.local",
+			ClientID:        "client",
+			ClientSecret:    secret,
+			ServiceIdentify: identify,
+			IsEnabledCache:  true,
+			TLS:             tls,
+		})
+	}
+	a, err := register("A", "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := register("B", "secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.tokenHandle() != b.tokenHandle() {
+		t.Fatal("expected shared token handle")
+	}
+
+	// 相同客户端但TLS配置不一致
+	if _, err := register("C", "secret", &TLSConfig{ServerName: "as.internal"}); err == nil {
+		t.Fatal("expected conflicting config error")
+	}
+	// 秘钥不同的服务不共享令牌
+	d, err := register("D", "other", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.tokenHandle() == a.tokenHandle() {
+		t.Fatal("expected separate token handle")
+	}
+
+	// 更新凭据只影响当前服务
+	a.SetCredentials("client", "rotated")
+	if _, secret := a.Credentials(); secret != "rotated" {
+		t.Fatalf("unexpected secret: %s", secret)
+	}
+	if _, secret := b.Credentials(); secret != "secret" {
+		t.Fatalf("credentials of B changed: %s", secret)
+	}
+	a.SetCredentials("client", "other")
+	if a.tokenHandle() != d.tokenHandle() {
+		t.Fatal("expected token handle of D")
+	}
+
+	// 共享的接口缓存按服务隔离
+	req := &GetUserCodeRequest{UID: "u1"}
+	a.setRouterCache("/api/authorize/usercode", req, map[string]string{"UserCode": "A001"})
+	if _, ok := b.getFromRouterCache("/api/authorize/usercode", req); ok {
+		t.Fatal("B read the cached result of A")
+	}
+	if v, ok := a.getFromRouterCache("/api/authorize/usercode", req); !ok || string(v) != `{"UserCode":"A001"}` {
+		t.Fatalf("unexpected cache: %s, %v", v, ok)
+	}
+}
@@ -0,0 +1,221 @@
+package asapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync"
+	"time"
+)
+
+// tlsVersions 支持的TLS最低版本
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSConfig 请求授权服务使用的TLS配置，证书文件在第一次建立连接时加载
+type TLSConfig struct {
+	CertFile       string // 客户端证书文件(PEM)，用于双向认证
+	KeyFile        string // 客户端私钥文件(PEM)
+	CAFile         string // 验证授权服务证书的CA证书文件(PEM)，为空时使用系统CA
+	ServerName     string // 验证授权服务证书使用的主机名，为空时使用请求URL中的主机名
+	MinVersion     string // TLS最低版本：1.0、1.1、1.2（默认）或1.3
+	ReloadInterval int    // 检查证书文件是否变化的间隔(单位秒)，文件变化时重新加载，0表示不重新加载
+}
+
+// Validate 检查TLS配置参数
+func (c *TLSConfig) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("asapi: 客户端证书(TLS.CertFile)和私钥(TLS.KeyFile)需要同时指定")
+	}
+	if _, ok := tlsVersions[c.MinVersion]; c.MinVersion != "" && !ok {
+		return fmt.Errorf("asapi: 不支持的TLS版本(TLS.MinVersion)：%s", c.MinVersion)
+	}
+	if c.ReloadInterval < 0 {
+		return errors.New("asapi: 证书重新加载间隔(TLS.ReloadInterval)不能小于0")
+	}
+	return nil
+}
+
+// newTLSConfig 创建连接池使用的tls.Config，cfg为nil时使用默认配置；
+// 指定了CAFile时同时返回建立TLS连接的dialTLS，否则dialTLS为nil
+func newTLSConfig(cfg *TLSConfig, dialer *net.Dialer) (tc *tls.Config, dialTLS func(ctx context.Context, network, addr string) (net.Conn, error)) {
+	if cfg == nil {
+		tc = &tls.Config{}
+		return
+	}
+
+	l := &tlsLoader{cfg: cfg}
+	tc = &tls.Config{
+		ServerName: cfg.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if v, ok := tlsVersions[cfg.MinVersion]; ok {
+		tc.MinVersion = v
+	}
+	if cfg.CertFile != "" {
+		tc.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _, err := l.load()
+			return cert, err
+		}
+	}
+	if cfg.CAFile != "" {
+		// CA证书可能被重新加载，因此不使用RootCAs，而是在VerifyConnection中使用当前的CA证书验证；
+		// 通过代理建立的连接不经过dialTLS，使用握手时的ServerName验证
+		tc.InsecureSkipVerify = true
+		tc.VerifyConnection = func(cs tls.ConnectionState) error {
+			return l.verify(cs, cs.ServerName)
+		}
+		dialTLS = l.dialTLS(tc, dialer)
+	}
+	return
+}
+
+// tlsLoader 加载并按需重新加载证书文件
+type tlsLoader struct {
+	cfg     *TLSConfig
+	lock    sync.Mutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	stamp   string    // 证书文件内容的哈希值
+	checked time.Time // 最后一次检查证书文件的时间
+}
+
+// load 返回当前的客户端证书和CA证书，首次调用或证书文件变化时重新加载，
+// 重新加载失败时继续使用原有的证书
+func (l *tlsLoader) load() (*tls.Certificate, *x509.CertPool, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	loaded := l.cert != nil || l.pool != nil
+	if loaded {
+		interval := time.Duration(l.cfg.ReloadInterval) * time.Second
+		if interval <= 0 || time.Since(l.checked) < interval {
+			return l.cert, l.pool, nil
+		}
+	}
+	l.checked = time.Now()
+
+	stamp := fileStamp(l.cfg.CertFile, l.cfg.KeyFile, l.cfg.CAFile)
+	if loaded && stamp == l.stamp {
+		return l.cert, l.pool, nil
+	}
+
+	cert, pool, err := l.read()
+	if err != nil {
+		if loaded {
+			return l.cert, l.pool, nil
+		}
+		return nil, nil, err
+	}
+	l.cert, l.pool, l.stamp = cert, pool, stamp
+	return cert, pool, nil
+}
+
+func (l *tlsLoader) read() (cert *tls.Certificate, pool *x509.CertPool, err error) {
+	if l.cfg.CertFile != "" {
+		c, verr := tls.LoadX509KeyPair(l.cfg.CertFile, l.cfg.KeyFile)
+		if verr != nil {
+			err = fmt.Errorf("asapi: 加载客户端证书失败：%s", verr)
+			return
+		}
+		cert = &c
+	}
+	if l.cfg.CAFile != "" {
+		buf, verr := ioutil.ReadFile(l.cfg.CAFile)
+		if verr != nil {
+			err = fmt.Errorf("asapi: 加载CA证书失败：%s", verr)
+			return
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(buf) {
+			err = fmt.Errorf("asapi: CA证书文件%s中没有有效的证书", l.cfg.CAFile)
+			return
+		}
+	}
+	return
+}
+
+// dialTLS 建立TLS连接，使用实际连接的主机（域名或IP）验证授权服务的证书。
+// 连接IP地址时crypto/tls不发送SNI，ConnectionState.ServerName为空，不能用于验证主机名
+func (l *tlsLoader) dialTLS(base *tls.Config, dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		tc := base.Clone()
+		if tc.ServerName == "" {
+			tc.ServerName = host
+		}
+		tc.VerifyConnection = func(cs tls.ConnectionState) error {
+			return l.verify(cs, host)
+		}
+		tlsConn := tls.Client(conn, tc)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
+
+// verify 使用CA证书验证授权服务的证书链和主机名，配置了ServerName时使用ServerName，否则使用host，
+// 无法确定主机名时拒绝连接
+func (l *tlsLoader) verify(cs tls.ConnectionState, host string) error {
+	_, pool, err := l.load()
+	if err != nil {
+		return err
+	}
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("asapi: 授权服务未提供证书")
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         pool,
+		DNSName:       host,
+		Intermediates: x509.NewCertPool(),
+	}
+	if l.cfg.ServerName != "" {
+		opts.DNSName = l.cfg.ServerName
+	}
+	if opts.DNSName == "" {
+		return errors.New("asapi: 无法确定验证授权服务证书的主机名，请指定TLS.ServerName")
+	}
+	for _, c := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(c)
+	}
+	_, err = cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// fileStamp 返回文件内容的哈希值，文件在同一时刻被替换时修改时间可能不变，因此不使用修改时间
+func fileStamp(names ...string) string {
+	h := sha256.New()
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		buf, err := ioutil.ReadFile(name)
+		if err != nil {
+			h.Write([]byte("-"))
+			continue
+		}
+		h.Write(buf)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,211 @@
+package asapi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCert 生成自签名的客户端证书，返回证书和私钥文件
+func writeTestCert(t *testing.T, dir, name string) (certFile, keyFile string, cert *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	cert, _ = x509.ParseCertificate(der)
+	return
+}
+
+func writePEM(t *testing.T, name, typ string, der []byte) {
+	if err := ioutil.WriteFile(name, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, clientCert := writeTestCert(t, dir, "client")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	var peer string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peer = r.TLS.PeerCertificates[0].Subject.CommonName
+		w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	defer srv.Close()
+
+	caFile := filepath.Join(dir, "ca.crt")
+	writePEM(t, caFile, "CERTIFICATE", srv.Certificate().Raw)
+
+	cfg := &Config{
+		ASURL:           srv.URL,
+		ServiceIdentify: "TEST",
+		TLS: &TLSConfig{
+			CertFile:   certFile,
+			KeyFile:    keyFile,
+			CAFile:     caFile,
+			ServerName: "example.com",
+			MinVersion: "1.2",
+		},
+	}
+	if err := cfg.TLS.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	ah := NewAuthorizeHandle(cfg)
+	defer ah.Close()
+	if _, result := ah.GetToken(); result != nil {
+		t.Fatal(result)
+	}
+	if peer != "client" {
+		t.Fatalf("unexpected client certificate: %s", peer)
+	}
+
+	// 主机名与证书不匹配
+	cfg.TLS.ServerName = "as.internal"
+	ah = NewAuthorizeHandle(cfg)
+	defer ah.Close()
+	if _, result := ah.GetToken(); result == nil {
+		t.Fatal("expected certificate verification error")
+	}
+}
+
+func TestTLSReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, _ := writeTestCert(t, dir, "old")
+
+	l := &tlsLoader{cfg: &TLSConfig{CertFile: certFile, KeyFile: keyFile, ReloadInterval: 60}}
+	cert, _, err := l.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newCert, newKey, _ := writeTestCert(t, dir, "new")
+	os.Rename(newCert, certFile)
+	os.Rename(newKey, keyFile)
+
+	// 未到检查间隔时不重新加载
+	if c, _, _ := l.load(); c != cert {
+		t.Fatal("certificate reloaded before interval")
+	}
+
+	l.checked = time.Time{}
+	c, _, err := l.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaf, _ := x509.ParseCertificate(c.Certificate[0]); leaf.Subject.CommonName != "new" {
+		t.Fatalf("unexpected certificate: %s", leaf.Subject.CommonName)
+	}
+
+	// 重新加载失败时继续使用原有的证书
+	os.Remove(keyFile)
+	l.checked = time.Time{}
+	if c2, _, err := l.load(); err != nil || c2 != c {
+		t.Fatalf("unexpected reload result: %v", err)
+	}
+}
+
+// TestTLSVerifyIPHost 使用IP地址连接且未指定ServerName时，证书必须包含该IP
+func TestTLSVerifyIPHost(t *testing.T) {
+	dir := t.TempDir()
+
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	// 同一CA签发的其他主机的证书
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "as.internal"},
+		DNSNames:     []string{"as.internal"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+	}))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	srv.StartTLS()
+	defer srv.Close()
+
+	caFile := filepath.Join(dir, "ca.crt")
+	writePEM(t, caFile, "CERTIFICATE", caDER)
+
+	cfg := &Config{ASURL: srv.URL, ServiceIdentify: "TEST", TLS: &TLSConfig{CAFile: caFile}}
+	ah := NewAuthorizeHandle(cfg)
+	defer ah.Close()
+	if _, result := ah.GetToken(); result == nil {
+		t.Fatal("expected certificate verification error for IP host")
+	}
+
+	cfg.TLS.ServerName = "as.internal"
+	ah = NewAuthorizeHandle(cfg)
+	defer ah.Close()
+	if _, result := ah.GetToken(); result != nil {
+		t.Fatal(result)
+	}
+
+	// 无法确定主机名时拒绝
+	l := &tlsLoader{cfg: &TLSConfig{CAFile: caFile}}
+	leaf, _ := x509.ParseCertificate(der)
+	if err := l.verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf}}, ""); err == nil {
+		t.Fatal("expected error without host name")
+	}
+}
@@ -1,12 +1,18 @@
 package asapi
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"sync"
 	"time"
 
 	"io/ioutil"
 
+	"github.com/antlinker/sdk/logging"
+	"github.com/antlinker/sdk/metrics"
+	"github.com/antlinker/sdk/redact"
+	"github.com/antlinker/sdk/tracing"
 	"github.com/astaxie/beego/httplib"
 )
 
@@ -19,50 +25,178 @@ type Token struct {
 
 // NewTokenHandle 创建令牌验证
 func NewTokenHandle(cfg *Config) *TokenHandle {
-	t := &TokenHandle{
-		cfg: cfg,
-	}
-	if cfg.MaxConns == 0 {
-		t.transport = &http.Transport{
-			DisableKeepAlives: true,
-		}
-		return t
-	}
 	if cfg.MaxConns < 0 {
 		cfg.MaxConns = 10
 	}
-	t.transport = &http.Transport{
-		MaxConnsPerHost: cfg.MaxConns,
+	return &TokenHandle{
+		cfg:       cfg,
+		transport: newTransport(cfg.MaxConns, cfg.TLS),
+		endpoints: newEndpoints(cfg),
+	}
+}
+
+// newTransport 创建请求授权服务的连接池，maxConns为0时不保持连接，tlsCfg为nil时使用默认的TLS配置
+// httplib会在请求时填充Transport中未设置的TLSClientConfig、Proxy和Dial，
+// 这里预先设置，以便在并发请求之间安全地共享
+func newTransport(maxConns int, tlsCfg *TLSConfig) http.RoundTripper {
+	dialer := &net.Dialer{Timeout: time.Second * 60, KeepAlive: time.Second * 30}
+	tlsConfig, dialTLS := newTLSConfig(tlsCfg, dialer)
+	tr := &http.Transport{
+		TLSClientConfig:       tlsConfig,
+		DialTLSContext:        dialTLS,
+		Proxy:                 http.ProxyFromEnvironment,
+		Dial:                  dialer.Dial,
+		ResponseHeaderTimeout: time.Second * 60,
+	}
+	if maxConns == 0 {
+		tr.DisableKeepAlives = true
+		return tr
+	}
+	if maxConns < 0 {
+		maxConns = 10
 	}
-	return t
+	tr.MaxConnsPerHost = maxConns
+	return tr
 }
 
 // TokenHandle 令牌验证处理
 type TokenHandle struct {
 	cfg       *Config
 	lock      sync.Mutex
+	credLock  sync.RWMutex
 	token     *Token
 	transport http.RoundTripper
+	endpoints *endpoints
+	log       logging.Logger
+}
+
+// SetLogger 设置获取令牌使用的日志，为nil时使用logging.Default()
+func (th *TokenHandle) SetLogger(l logging.Logger) {
+	th.credLock.Lock()
+	th.log = l
+	th.credLock.Unlock()
+}
+
+func (th *TokenHandle) logger() logging.Logger {
+	th.credLock.RLock()
+	defer th.credLock.RUnlock()
+	return th.log
+}
+
+// SetTransport 设置请求授权服务使用的RoundTripper，用于替换默认的连接池（如录制和回放请求）
+func (th *TokenHandle) SetTransport(rt http.RoundTripper) {
+	th.credLock.Lock()
+	th.transport = rt
+	th.credLock.Unlock()
+}
+
+func (th *TokenHandle) roundTripper() http.RoundTripper {
+	th.credLock.RLock()
+	defer th.credLock.RUnlock()
+	return th.transport
+}
+
+// credentials 获取客户端ID和秘钥
+func (th *TokenHandle) credentials() (clientID, clientSecret string) {
+	th.credLock.RLock()
+	clientID, clientSecret = th.cfg.ClientID, th.cfg.ClientSecret
+	th.credLock.RUnlock()
+	return
+}
+
+// SetCredentials 更新客户端ID和秘钥，并丢弃已缓存的令牌
+func (th *TokenHandle) SetCredentials(clientID, clientSecret string) {
+	th.credLock.Lock()
+	th.cfg.ClientID, th.cfg.ClientSecret = clientID, clientSecret
+	th.credLock.Unlock()
+
+	th.lock.Lock()
+	th.token = nil
+	th.lock.Unlock()
+}
+
+// tokenRef 授权处理引用的令牌处理
+// 注册表中的令牌处理被多个授权处理共享，更新凭据时通过rebind切换到新凭据对应的令牌处理，不影响其他授权处理
+type tokenRef struct {
+	lock   sync.RWMutex
+	th     *TokenHandle
+	rebind func(old *TokenHandle, clientID, clientSecret string) *TokenHandle
+}
+
+func (r *tokenRef) get() *TokenHandle {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.th
+}
+
+func (r *tokenRef) setCredentials(clientID, clientSecret string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.rebind != nil {
+		r.th = r.rebind(r.th, clientID, clientSecret)
+		return
+	}
+	r.th.SetCredentials(clientID, clientSecret)
 }
 
 // ForceGet 强制获取最新的令牌数据
 func (th *TokenHandle) ForceGet() (token *Token, result *ErrorResult) {
-	req := httplib.Post(th.cfg.GetURL("/oauth2/token"))
-	req = req.SetBasicAuth(th.cfg.ClientID, th.cfg.ClientSecret)
-	req.SetTransport(th.transport)
+	return th.forceGet(context.Background())
+}
+
+// forceGet 依次向授权服务节点获取令牌，节点不可用时切换到下一个节点
+func (th *TokenHandle) forceGet(ctx context.Context) (token *Token, result *ErrorResult) {
+	eps := th.endpoints.order()
+	for i, ep := range eps {
+		start := time.Now()
+		var nerr *nodeError
+		token, result, nerr = th.forceGetURL(ctx, ep.url)
+		if result == nil || nerr != nil {
+			th.endpoints.observe(ep, time.Since(start), nerr != nil)
+		}
+		// 获取令牌不会修改授权服务的数据，节点不可用时总是可以切换
+		if nerr == nil || i == len(eps)-1 {
+			return
+		}
+	}
+	return
+}
+
+func (th *TokenHandle) forceGetURL(ctx context.Context, baseURL string) (token *Token, result *ErrorResult, nerr *nodeError) {
+	url := joinURL(baseURL, "/oauth2/token")
+	req := httplib.Post(url)
+	req = req.SetBasicAuth(th.credentials())
+	req.SetTransport(th.roundTripper())
 	req = req.Param("grant_type", "client_credentials")
+
+	_, span := tracing.StartHTTP(ctx, "asapi /oauth2/token", http.MethodPost, url, req.GetRequest().Header)
+	var status int
+	start := time.Now()
+	defer func() {
+		endSpan(span, status, result)
+		metrics.ObserveRequest(metrics.ComponentASAPI, "/oauth2/token", resultCode(result), start)
+		logResult(logging.Or(th.logger()), "/oauth2/token", http.MethodPost, status, start, result, nil)
+	}()
+
 	res, err := req.Response()
+	if err == nil {
+		status = res.StatusCode
+	}
 	if err != nil {
 		result = NewErrorResult(err.Error())
+		nerr = &nodeError{err: err}
 		return
 	} else if res.StatusCode != 200 {
+		if unavailable(res.StatusCode) {
+			nerr = &nodeError{status: res.StatusCode}
+		}
 		defer res.Body.Close()
 		buf, err := ioutil.ReadAll(res.Body)
 		if err != nil {
 			result = NewErrorResult(err.Error())
 			return
 		}
-		result = NewErrorResult(string(buf), res.StatusCode)
+		result = NewErrorResult(redact.String(string(buf)), res.StatusCode)
 		return
 	}
 	var t Token
@@ -78,11 +212,15 @@ func (th *TokenHandle) ForceGet() (token *Token, result *ErrorResult) {
 
 // Get 获取令牌
 func (th *TokenHandle) Get() (tokenString string, result *ErrorResult) {
+	return th.get(context.Background())
+}
+
+func (th *TokenHandle) get(ctx context.Context) (tokenString string, result *ErrorResult) {
 	th.lock.Lock()
 	defer th.lock.Unlock()
 	if th.token == nil ||
 		th.token.CreateTime.Add(time.Duration(th.token.ExpiresIn-10)*time.Second).Before(time.Now()) {
-		token, vresult := th.ForceGet()
+		token, vresult := th.forceGet(ctx)
 		if vresult != nil {
 			result = vresult
 			return
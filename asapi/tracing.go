@@ -0,0 +1,44 @@
+package asapi
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/antlinker/sdk/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithContext 返回使用ctx的授权处理副本，副本发起的请求以ctx中的span为父span，ctx取消时中断请求，
+// 并与原授权处理共享配置、令牌、缓存和连接池
+func (ah *AuthorizeHandle) WithContext(ctx context.Context) *AuthorizeHandle {
+	nah := new(AuthorizeHandle)
+	*nah = *ah
+	nah.ctx = ctx
+	return nah
+}
+
+func (ah *AuthorizeHandle) context() context.Context {
+	if ah.ctx != nil {
+		return ah.ctx
+	}
+	return context.Background()
+}
+
+// endSpan 记录请求结果并结束span
+func endSpan(span trace.Span, status int, result *ErrorResult) {
+	if result != nil {
+		tracing.EndHTTP(span, status, result)
+		return
+	}
+	tracing.EndHTTP(span, status, nil)
+}
+
+// resultCode 错误结果在指标中的错误码，请求未得到响应时为error
+func resultCode(result *ErrorResult) string {
+	if result == nil {
+		return ""
+	} else if result.Code == 0 {
+		return "error"
+	}
+	return strconv.Itoa(result.Code)
+}
@@ -0,0 +1,260 @@
+package asapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SyncState 用户的同步状态
+type SyncState struct {
+	Version   int  // 已同步的用户版本
+	ClearAuth int  // 已同步的清理认证状态
+	Synced    bool // 是否已经同步过
+}
+
+// SyncStore 用户同步状态的存储，可以使用数据库等实现持久化
+type SyncStore interface {
+	// Load 返回所有需要同步的用户及其同步状态
+	Load() (map[string]SyncState, error)
+	// Save 保存用户的同步状态，用户不存在时增加
+	Save(uid string, state SyncState) error
+	// Delete 删除用户，不再同步
+	Delete(uid string) error
+}
+
+// NewMemorySyncStore 创建基于内存的同步状态存储
+func NewMemorySyncStore() *MemorySyncStore {
+	return &MemorySyncStore{states: make(map[string]SyncState)}
+}
+
+// MemorySyncStore 基于内存的同步状态存储
+type MemorySyncStore struct {
+	lock   sync.RWMutex
+	states map[string]SyncState
+}
+
+// Load 返回所有需要同步的用户及其同步状态
+func (s *MemorySyncStore) Load() (map[string]SyncState, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	states := make(map[string]SyncState, len(s.states))
+	for uid, state := range s.states {
+		states[uid] = state
+	}
+	return states, nil
+}
+
+// Save 保存用户的同步状态
+func (s *MemorySyncStore) Save(uid string, state SyncState) error {
+	s.lock.Lock()
+	s.states[uid] = state
+	s.lock.Unlock()
+	return nil
+}
+
+// Delete 删除用户
+func (s *MemorySyncStore) Delete(uid string) error {
+	s.lock.Lock()
+	delete(s.states, uid)
+	s.lock.Unlock()
+	return nil
+}
+
+// SyncEventType 同步事件类型
+type SyncEventType string
+
+// 同步事件类型
+const (
+	SyncUserUpdated     SyncEventType = "updated"     // 用户版本变化（包括首次同步），Update为更新信息
+	SyncUserAuthCleared SyncEventType = "authcleared" // 用户需要清理认证信息
+	SyncUserDeleted     SyncEventType = "deleted"     // 用户不存在（GetUserVersion返回错误码11），处理成功后不再同步
+)
+
+// SyncEvent 同步事件
+type SyncEvent struct {
+	Type     SyncEventType
+	UID      string
+	Previous SyncState             // 同步前的状态
+	Version  *GetUserVersionResult // 当前的用户版本，删除事件为nil
+	Update   *GetUserUpdateResult  // 用户更新信息，只有更新事件有值
+}
+
+// SyncOptions 同步参数
+type SyncOptions struct {
+	Store       SyncStore                    // 同步状态存储，默认使用内存
+	Interval    time.Duration                // Run的轮询间隔，默认1分钟
+	Concurrency int                          // 并发请求数量，默认10
+	Handler     func(event *SyncEvent) error // 事件处理（可能被并发调用），返回错误时不保存同步状态，下次轮询重新处理
+}
+
+// SyncReport 一次轮询的结果
+type SyncReport struct {
+	Total   int              // 同步的用户数量
+	Changed int              // 发生变化的用户数量
+	Failed  int              // 失败的用户数量
+	Errors  map[string]error // 失败用户的错误（*ErrorResult或事件处理返回的错误）
+}
+
+// Syncer 轮询用户版本，将用户信息的变化同步到本地
+type Syncer struct {
+	ah   *AuthorizeHandle
+	opts SyncOptions
+	lock sync.Mutex // 保证同一时间只有一次轮询
+}
+
+// NewSyncer 创建用户同步
+func (ah *AuthorizeHandle) NewSyncer(opts *SyncOptions) *Syncer {
+	s := &Syncer{ah: ah}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Store == nil {
+		s.opts.Store = NewMemorySyncStore()
+	}
+	if s.opts.Interval <= 0 {
+		s.opts.Interval = time.Minute
+	}
+	if s.opts.Concurrency <= 0 {
+		s.opts.Concurrency = 10
+	}
+	if s.opts.Handler == nil {
+		s.opts.Handler = func(*SyncEvent) error { return nil }
+	}
+	return s
+}
+
+// Track 增加需要同步的用户，已存在的用户保持原有状态
+func (s *Syncer) Track(uids ...string) error {
+	states, err := s.opts.Store.Load()
+	if err != nil {
+		return err
+	}
+	for _, uid := range uids {
+		if _, ok := states[uid]; ok {
+			continue
+		}
+		if err := s.opts.Store.Save(uid, SyncState{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Untrack 删除需要同步的用户
+func (s *Syncer) Untrack(uids ...string) error {
+	for _, uid := range uids {
+		if err := s.opts.Store.Delete(uid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run 立即轮询一次，之后按间隔轮询，直到ctx结束
+// fn 每次轮询完成后的回调，可以为nil
+func (s *Syncer) Run(ctx context.Context, fn func(*SyncReport, error)) error {
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+	for {
+		report, err := s.Poll(ctx)
+		if fn != nil {
+			fn(report, err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Poll 轮询一次所有需要同步的用户：
+// 获取用户版本，版本变化时获取更新信息并发送更新事件，需要清理认证信息时发送清理事件，
+// 事件处理成功后保存同步状态
+func (s *Syncer) Poll(ctx context.Context) (*SyncReport, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	states, err := s.opts.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		report = &SyncReport{Errors: make(map[string]error)}
+		lock   sync.Mutex
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, s.opts.Concurrency)
+		ah     = s.ah.WithContext(ctx) // 轮询取消时中断进行中的请求
+	)
+
+	for uid, state := range states {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(uid string, state SyncState) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			changed, err := s.syncUser(ah, uid, state)
+
+			lock.Lock()
+			defer lock.Unlock()
+			report.Total++
+			if changed {
+				report.Changed++
+			}
+			if err != nil {
+				report.Failed++
+				report.Errors[uid] = err
+			}
+		}(uid, state)
+	}
+	wg.Wait()
+
+	return report, ctx.Err()
+}
+
+// syncUser 同步单个用户，返回用户是否发生变化
+func (s *Syncer) syncUser(ah *AuthorizeHandle, uid string, prev SyncState) (changed bool, err error) {
+	version, result := ah.GetUserVersion(uid)
+	if result != nil {
+		if !result.IsUserNotFound() {
+			return false, result
+		}
+		if err = s.opts.Handler(&SyncEvent{Type: SyncUserDeleted, UID: uid, Previous: prev}); err != nil {
+			return true, err
+		}
+		return true, s.opts.Store.Delete(uid)
+	}
+
+	var events []*SyncEvent
+	if !prev.Synced || version.Version != prev.Version {
+		update, result := ah.GetUserUpdate(uid)
+		if result != nil {
+			return false, result
+		}
+		events = append(events, &SyncEvent{Type: SyncUserUpdated, UID: uid, Previous: prev, Version: version, Update: update})
+	}
+	if version.ClearAuth == 1 && prev.ClearAuth != 1 {
+		events = append(events, &SyncEvent{Type: SyncUserAuthCleared, UID: uid, Previous: prev, Version: version})
+	}
+	if len(events) == 0 && version.ClearAuth == prev.ClearAuth {
+		return false, nil
+	}
+
+	for _, e := range events {
+		if err = s.opts.Handler(e); err != nil {
+			return true, err
+		}
+	}
+	return true, s.opts.Store.Save(uid, SyncState{Version: version.Version, ClearAuth: version.ClearAuth, Synced: true})
+}
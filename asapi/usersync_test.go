@@ -0,0 +1,151 @@
+package asapi_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/asapi/fakeas"
+)
+
+func TestSyncer(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		versions = map[string]*asapi.GetUserVersionResult{
+			"u1": {Version: 1},
+			"u2": {Version: 1},
+		}
+	)
+	s := &fakeas.Server{}
+	s.GetUserVersion = func(req map[string]interface{}) (*asapi.GetUserVersionResult, *asapi.ErrorResult) {
+		lock.Lock()
+		defer lock.Unlock()
+		uid := req["UID"].(string)
+		v, ok := versions[uid]
+		switch {
+		case uid == "u4":
+			// 路由错误、代理等返回的404不是用户不存在
+			return nil, asapi.NewErrorResult("not found", http.StatusNotFound)
+		case !ok:
+			return nil, asapi.NewErrorResult(`{"code":11,"message":"未知的用户"}`, http.StatusBadRequest)
+		}
+		cp := *v
+		return &cp, nil
+	}
+	s.GetUserUpdate = func(req map[string]interface{}) (*asapi.GetUserUpdateResult, *asapi.ErrorResult) {
+		return &asapi.GetUserUpdateResult{RealName: "name-" + req["UID"].(string)}, nil
+	}
+	ah := newFakeHandle(t, s, nil)
+
+	var (
+		events  []string
+		failure error
+	)
+	syncer := ah.NewSyncer(&asapi.SyncOptions{
+		Concurrency: 2,
+		Handler: func(e *asapi.SyncEvent) error {
+			lock.Lock()
+			defer lock.Unlock()
+			if failure != nil {
+				return failure
+			}
+			events = append(events, string(e.Type)+":"+e.UID)
+			if e.Type == asapi.SyncUserUpdated && e.Update.RealName != "name-"+e.UID {
+				t.Errorf("unexpected update: %+v", e.Update)
+			}
+			return nil
+		},
+	})
+	poll := func(want ...string) *asapi.SyncReport {
+		t.Helper()
+		events = nil
+		report, err := syncer.Poll(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(events)
+		sort.Strings(want)
+		if len(events) != len(want) {
+			t.Fatalf("unexpected events: %v, want %v", events, want)
+		}
+		for i := range want {
+			if events[i] != want[i] {
+				t.Fatalf("unexpected events: %v, want %v", events, want)
+			}
+		}
+		return report
+	}
+
+	if err := syncer.Track("u1", "u2", "u3", "u4"); err != nil {
+		t.Fatal(err)
+	}
+	if report := poll("updated:u1", "updated:u2", "deleted:u3"); report.Total != 4 || report.Changed != 3 || report.Failed != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if err := syncer.Untrack("u4"); err != nil {
+		t.Fatal(err)
+	}
+	poll()
+
+	versions["u1"].Version = 2
+	versions["u2"].ClearAuth = 1
+	poll("updated:u1", "authcleared:u2")
+
+	// 事件处理失败时下次轮询重新处理
+	versions["u2"].Version = 2
+	failure = errors.New("db error")
+	if report := poll(); report.Failed != 1 || report.Errors["u2"] != failure {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	failure = nil
+	poll("updated:u2")
+
+	// 清理状态恢复后再次清理
+	versions["u2"].ClearAuth = 0
+	poll()
+	versions["u2"].ClearAuth = 1
+	poll("authcleared:u2")
+
+	if err := syncer.Untrack("u1", "u2"); err != nil {
+		t.Fatal(err)
+	}
+	if report := poll(); report.Total != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+func TestSyncerCancel(t *testing.T) {
+	release := make(chan struct{})
+	s := &fakeas.Server{}
+	s.GetUserVersion = func(map[string]interface{}) (*asapi.GetUserVersionResult, *asapi.ErrorResult) {
+		<-release
+		return &asapi.GetUserVersionResult{Version: 1}, nil
+	}
+	ah := newFakeHandle(t, s, nil)
+	t.Cleanup(func() { close(release) })
+
+	syncer := ah.NewSyncer(nil)
+	if err := syncer.Track("u1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 取消轮询时中断进行中的请求
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	report, err := syncer.Poll(ctx)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("poll took %v", d)
+	}
+	if report.Failed != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
@@ -0,0 +1,77 @@
+package asapi
+
+import (
+	"errors"
+
+	"github.com/antlinker/sdk/validation"
+)
+
+// Validate 校验增加学工用户请求的必填字段以及手机号、身份证号和性别
+func (r *AddStaffUserRequest) Validate() error {
+	var errs validation.Errors
+	errs.Required("UID", "用户ID", r.UID)
+	errs.Required("University", "学校ID", r.University)
+	errs.Required("UserCode", "学号", r.UserCode)
+	errs.Check("MobilePhone", validation.MobilePhone(r.MobilePhone))
+	errs.Check("IDCard", validation.IDCard(r.IDCard))
+	errs.Check("Sex", validation.Sex(string(r.Sex)))
+	return errs.Err()
+}
+
+// Validate 校验增加用户请求的必填字段以及手机号和身份证号
+func (r *AuthorizeAddUserRequest) Validate() error {
+	var errs validation.Errors
+	errs.Required("University", "学校ID", r.University)
+	errs.Required("UserCode", "学号", r.UserCode)
+	errs.Check("MobilePhone", validation.MobilePhone(r.MobilePhone))
+	errs.Check("IDCard", validation.IDCard(r.IDCard))
+	return errs.Err()
+}
+
+// Validate 校验编辑用户请求的手机号和身份证号
+func (r *AuthorizeEditUserRequest) Validate() error {
+	var errs validation.Errors
+	errs.Check("MobilePhone", validation.MobilePhone(r.MobilePhone))
+	errs.Check("IDCard", validation.IDCard(r.IDCard))
+	return errs.Err()
+}
+
+// Validate 校验激活信息的必填字段以及手机号、身份证号、性别和用户类型
+func (r *UserActivateResult) Validate() error {
+	var errs validation.Errors
+	errs.Required("University", "学校ID", r.University)
+	errs.Required("UserCode", "学号", r.UserCode)
+	errs.Check("MobilePhone", validation.MobilePhone(r.MobilePhone))
+	errs.Check("IDCard", validation.IDCard(r.IDCard))
+	errs.Check("Sex", validation.Sex(string(r.Sex)))
+	errs.Check("UserType", r.UserType.Validate())
+	return errs.Err()
+}
+
+// validator 可以校验的请求
+type validator interface {
+	Validate() error
+}
+
+// validate 配置了ValidateRequests时在发送请求前校验参数，指定uid时同时校验用户ID
+func (ah *AuthorizeHandle) validate(req validator, uid ...string) *ErrorResult {
+	if !ah.cfg.ValidateRequests {
+		return nil
+	}
+
+	var errs validation.Errors
+	if len(uid) > 0 {
+		errs.Required("UID", "用户ID", uid[0])
+	}
+	if err := req.Validate(); err != nil {
+		var verrs validation.Errors
+		if !errors.As(err, &verrs) {
+			return NewErrorResult(err.Error())
+		}
+		errs = append(errs, verrs...)
+	}
+	if err := errs.Err(); err != nil {
+		return NewErrorResult(err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package asapi_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/asapi/fakeas"
+)
+
+func TestValidateRequests(t *testing.T) {
+	var calls int
+	s := &fakeas.Server{}
+	s.AddUser = func(map[string]interface{}) *asapi.ErrorResult {
+		calls++
+		return nil
+	}
+
+	user := &asapi.AuthorizeAddUserRequest{University: "u", UserCode: "c", MobilePhone: "123", IDCard: "110105194912310021"}
+
+	ah := newFakeHandle(t, s, &asapi.Config{ValidateRequests: true})
+	result := ah.AddUser("", user)
+	if result == nil || calls != 0 {
+		t.Fatalf("expected validation error, got %v", result)
+	}
+	for _, s := range []string{"用户ID不能为空", "无效的手机号", "校验码错误"} {
+		if !strings.Contains(result.Message, s) {
+			t.Errorf("missing %q in %q", s, result.Message)
+		}
+	}
+	// 错误信息中不包含手机号、身份证号
+	if strings.Contains(result.Message, user.IDCard) || strings.Contains(result.Message, "："+user.MobilePhone) {
+		t.Errorf("message contains personal information: %q", result.Message)
+	}
+
+	user.MobilePhone, user.IDCard = "13800000000", "11010519491231002X"
+	if result = ah.AddUser("u1", user); result != nil || calls != 1 {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	// 未开启时不校验
+	noValidate := newFakeHandle(t, s, nil)
+	if result = noValidate.AddUser("u1", &asapi.AuthorizeAddUserRequest{MobilePhone: "123"}); result != nil || calls != 2 {
+		t.Fatalf("unexpected result: %v", result)
+	}
+}
@@ -0,0 +1,341 @@
+package asapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/antlinker/go-cache"
+)
+
+// WebhookEventType 授权服务通知事件类型
+type WebhookEventType string
+
+// 授权服务通知的用户生命周期事件
+const (
+	EventUserUpdated         WebhookEventType = "user.updated"         // 用户信息更新
+	EventUserDeleted         WebhookEventType = "user.deleted"         // 用户删除
+	EventUserMerged          WebhookEventType = "user.merged"          // 用户合并
+	EventUserAuthCleared     WebhookEventType = "user.authcleared"     // 用户认证信息清理
+	EventUserActivated       WebhookEventType = "user.activated"       // 用户激活
+	EventUserPasswordChanged WebhookEventType = "user.passwordchanged" // 用户密码修改
+)
+
+var (
+	// ErrWebhookQueueFull 通知队列已满
+	ErrWebhookQueueFull = errors.New("webhook queue is full")
+	// ErrWebhookClosed 通知分发已关闭
+	ErrWebhookClosed = errors.New("webhook dispatcher is closed")
+)
+
+// WebhookEvent 授权服务通知事件
+type WebhookEvent struct {
+	ID   string           `json:"EventID"`   // 事件ID（用于幂等处理）
+	Type WebhookEventType `json:"EventType"` // 事件类型
+	UID  string           `json:"-"`         // 用户ID（来自BasicAuth）
+	Time time.Time        `json:"EventTime"` // 事件发生时间
+	Data json.RawMessage  `json:"Data"`      // 事件数据
+}
+
+// UserUpdatedEvent 用户信息更新事件
+type UserUpdatedEvent struct {
+	*WebhookEvent `json:"-"`
+	UserInfo
+}
+
+// UserDeletedEvent 用户删除事件
+type UserDeletedEvent struct {
+	*WebhookEvent `json:"-"`
+	University    string // 学校ID
+}
+
+// UserMergedEvent 用户合并事件
+type UserMergedEvent struct {
+	*WebhookEvent `json:"-"`
+	TUID          string // 被合并的用户ID
+	TUserCode     string // 被合并用户的学号
+	TUniversity   string // 被合并用户的学校ID
+}
+
+// UserAuthClearedEvent 用户认证信息清理事件
+type UserAuthClearedEvent struct {
+	*WebhookEvent `json:"-"`
+	University    string // 学校ID
+}
+
+// UserActivatedEvent 用户激活事件
+type UserActivatedEvent struct {
+	*WebhookEvent `json:"-"`
+	UserActivateResult
+}
+
+// UserPasswordChangedEvent 用户密码修改事件
+type UserPasswordChangedEvent struct {
+	*WebhookEvent `json:"-"`
+	IsDefault     bool // 是否为默认密码
+}
+
+// WebhookConfig 通知分发配置参数
+type WebhookConfig struct {
+	ServiceIdentify string // 服务标识（用于校验通知来源）
+	QueueSize       int    // 队列长度，默认1000
+	Workers         int    // 处理协程数量，默认1（保证事件顺序）
+	IdempotencyTTL  int    // 事件ID去重的保留时间(单位秒)，默认3600
+}
+
+// NewWebhookDispatcher 创建授权服务通知分发
+func NewWebhookDispatcher(cfg *WebhookConfig) *WebhookDispatcher {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.IdempotencyTTL <= 0 {
+		cfg.IdempotencyTTL = 3600
+	}
+
+	d := &WebhookDispatcher{
+		cfg:      cfg,
+		handlers: make(map[WebhookEventType]func(*WebhookEvent) error),
+		seen:     cache.New(time.Duration(cfg.IdempotencyTTL)*time.Second, 0),
+		queue:    make(chan *WebhookEvent, cfg.QueueSize),
+	}
+	d.janitor = runJanitor(time.Duration(cfg.IdempotencyTTL)*time.Second, d.seen)
+
+	for i := 0; i < cfg.Workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+
+	return d
+}
+
+// WebhookDispatcher 授权服务通知分发
+// 收到通知后立即应答，事件放入队列中异步处理
+type WebhookDispatcher struct {
+	cfg      *WebhookConfig
+	lock     sync.RWMutex
+	handlers map[WebhookEventType]func(*WebhookEvent) error
+	onError  func(*WebhookEvent, error)
+	seenLock sync.Mutex
+	seen     *cache.Cache
+	janitor  *janitor
+	queue    chan *WebhookEvent
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+func (d *WebhookDispatcher) handle(typ WebhookEventType, h func(*WebhookEvent) error) {
+	d.lock.Lock()
+	d.handlers[typ] = h
+	d.lock.Unlock()
+}
+
+// OnUserUpdated 注册用户信息更新事件处理
+func (d *WebhookDispatcher) OnUserUpdated(h func(*UserUpdatedEvent) error) {
+	d.handle(EventUserUpdated, func(e *WebhookEvent) error {
+		ev := &UserUpdatedEvent{WebhookEvent: e}
+		if err := e.decode(&ev.UserInfo); err != nil {
+			return err
+		}
+		return h(ev)
+	})
+}
+
+// OnUserDeleted 注册用户删除事件处理
+func (d *WebhookDispatcher) OnUserDeleted(h func(*UserDeletedEvent) error) {
+	d.handle(EventUserDeleted, func(e *WebhookEvent) error {
+		ev := &UserDeletedEvent{WebhookEvent: e}
+		if err := e.decode(ev); err != nil {
+			return err
+		}
+		return h(ev)
+	})
+}
+
+// OnUserMerged 注册用户合并事件处理
+func (d *WebhookDispatcher) OnUserMerged(h func(*UserMergedEvent) error) {
+	d.handle(EventUserMerged, func(e *WebhookEvent) error {
+		ev := &UserMergedEvent{WebhookEvent: e}
+		if err := e.decode(ev); err != nil {
+			return err
+		}
+		return h(ev)
+	})
+}
+
+// OnUserAuthCleared 注册用户认证信息清理事件处理
+func (d *WebhookDispatcher) OnUserAuthCleared(h func(*UserAuthClearedEvent) error) {
+	d.handle(EventUserAuthCleared, func(e *WebhookEvent) error {
+		ev := &UserAuthClearedEvent{WebhookEvent: e}
+		if err := e.decode(ev); err != nil {
+			return err
+		}
+		return h(ev)
+	})
+}
+
+// OnUserActivated 注册用户激活事件处理
+func (d *WebhookDispatcher) OnUserActivated(h func(*UserActivatedEvent) error) {
+	d.handle(EventUserActivated, func(e *WebhookEvent) error {
+		ev := &UserActivatedEvent{WebhookEvent: e}
+		if err := e.decode(&ev.UserActivateResult); err != nil {
+			return err
+		}
+		return h(ev)
+	})
+}
+
+// OnUserPasswordChanged 注册用户密码修改事件处理
+func (d *WebhookDispatcher) OnUserPasswordChanged(h func(*UserPasswordChangedEvent) error) {
+	d.handle(EventUserPasswordChanged, func(e *WebhookEvent) error {
+		ev := &UserPasswordChangedEvent{WebhookEvent: e}
+		if err := e.decode(ev); err != nil {
+			return err
+		}
+		return h(ev)
+	})
+}
+
+// OnError 注册事件处理失败的回调
+// 处理失败的事件会从去重记录中移除，授权服务重发时可以再次处理
+func (d *WebhookDispatcher) OnError(h func(*WebhookEvent, error)) {
+	d.lock.Lock()
+	d.onError = h
+	d.lock.Unlock()
+}
+
+// ServeHTTP 接收授权服务的通知
+// 兼容RegisterUpdateUser的请求格式：未指定事件类型时按用户信息更新事件处理
+func (d *WebhookDispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	identify, uid, ok := r.BasicAuth()
+	if !ok || identify != d.cfg.ServiceIdentify {
+		http.Error(w, "未识别的用户信息", http.StatusUnauthorized)
+		return
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(raw, &event); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if event.Type == "" {
+		event.Type = EventUserUpdated
+	}
+	if len(event.Data) == 0 {
+		event.Data = raw
+	}
+	if event.ID == "" {
+		event.ID = r.Header.Get("X-Event-ID")
+	}
+	event.UID = uid
+
+	switch err := d.Dispatch(&event); err {
+	case nil:
+	case ErrWebhookQueueFull, ErrWebhookClosed:
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write([]byte("ok"))
+}
+
+// Dispatch 将事件放入处理队列，重复的事件ID将被忽略
+func (d *WebhookDispatcher) Dispatch(event *WebhookEvent) (err error) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if d.closed {
+		err = ErrWebhookClosed
+		return
+	}
+
+	if !d.markSeen(event.ID) {
+		return
+	}
+
+	select {
+	case d.queue <- event:
+	default:
+		d.forget(event.ID)
+		err = ErrWebhookQueueFull
+	}
+	return
+}
+
+// Close 停止接收事件，并等待队列中的事件处理完成
+func (d *WebhookDispatcher) Close() {
+	d.lock.Lock()
+	if d.closed {
+		d.lock.Unlock()
+		return
+	}
+	d.closed = true
+	close(d.queue)
+	d.lock.Unlock()
+
+	d.wg.Wait()
+	d.janitor.Stop()
+}
+
+func (d *WebhookDispatcher) work() {
+	defer d.wg.Done()
+	for event := range d.queue {
+		d.lock.RLock()
+		h := d.handlers[event.Type]
+		onError := d.onError
+		d.lock.RUnlock()
+
+		if h == nil {
+			continue
+		}
+
+		if err := h(event); err != nil {
+			d.forget(event.ID)
+			if onError != nil {
+				onError(event, err)
+			}
+		}
+	}
+}
+
+// markSeen 记录事件ID，如果已经处理过则返回false
+func (d *WebhookDispatcher) markSeen(id string) bool {
+	if id == "" {
+		return true
+	}
+	d.seenLock.Lock()
+	defer d.seenLock.Unlock()
+	if _, ok := d.seen.Get(id); ok {
+		return false
+	}
+	d.seen.Set(id, true, time.Duration(d.cfg.IdempotencyTTL)*time.Second)
+	return true
+}
+
+func (d *WebhookDispatcher) forget(id string) {
+	if id == "" {
+		return
+	}
+	d.seenLock.Lock()
+	d.seen.Delete(id)
+	d.seenLock.Unlock()
+}
+
+func (e *WebhookEvent) decode(v interface{}) error {
+	if len(e.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(e.Data, v)
+}
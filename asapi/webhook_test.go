@@ -0,0 +1,65 @@
+package asapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookDispatcher(t *testing.T) {
+	d := NewWebhookDispatcher(&WebhookConfig{ServiceIdentify: "TEST"})
+
+	var (
+		updated []*UserUpdatedEvent
+		deleted []*UserDeletedEvent
+	)
+	d.OnUserUpdated(func(e *UserUpdatedEvent) error {
+		updated = append(updated, e)
+		return nil
+	})
+	d.OnUserDeleted(func(e *UserDeletedEvent) error {
+		deleted = append(deleted, e)
+		return nil
+	})
+
+	post := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		req.SetBasicAuth("TEST", "AA0001")
+		w := httptest.NewRecorder()
+		d.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// 兼容旧的用户信息更新格式
+	if code := post(`{"MobilePhone":"13800000000","UserCode":"2017001"}`); code != http.StatusOK {
+		t.Fatalf("legacy update status: %d", code)
+	}
+	// 重复的事件ID只处理一次
+	for i := 0; i < 2; i++ {
+		if code := post(`{"EventID":"e1","EventType":"user.deleted","Data":{"University":"11906"}}`); code != http.StatusOK {
+			t.Fatalf("delete status: %d", code)
+		}
+	}
+	d.Close()
+
+	if len(updated) != 1 || updated[0].UID != "AA0001" || updated[0].MobilePhone != "13800000000" {
+		t.Fatalf("unexpected updated events: %+v", updated)
+	}
+	if len(deleted) != 1 || deleted[0].University != "11906" || deleted[0].ID != "e1" {
+		t.Fatalf("unexpected deleted events: %+v", deleted)
+	}
+}
+
+func TestWebhookDispatcherUnauthorized(t *testing.T) {
+	d := NewWebhookDispatcher(&WebhookConfig{ServiceIdentify: "TEST"})
+	defer d.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{}`))
+	req.SetBasicAuth("OTHER", "AA0001")
+	w := httptest.NewRecorder()
+	d.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status: %d", w.Code)
+	}
+}
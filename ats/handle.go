@@ -0,0 +1,150 @@
+package ats
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/antlinker/sdk/logging"
+	"github.com/antlinker/sdk/metrics"
+	"github.com/antlinker/sdk/redact"
+	"github.com/antlinker/sdk/utils"
+)
+
+// NewHandle 创建工具化服务处理
+func NewHandle(cfg *Config) *Handle {
+	return &Handle{
+		cfg:      cfg,
+		inflight: new(utils.InFlight),
+	}
+}
+
+// Handle 工具化服务处理
+type Handle struct {
+	cfg *Config
+	cli *http.Client
+
+	inflight *utils.InFlight
+	ctx      context.Context // 由WithContext设置，用于链路追踪
+	log      logging.Logger
+}
+
+// SetHTTPClient 设置请求使用的HTTP客户端，未设置时每次请求创建新的客户端
+func (h *Handle) SetHTTPClient(cli *http.Client) {
+	h.cli = cli
+}
+
+// SetLogger 设置工具化服务处理使用的日志，为nil时使用logging.Default()
+func (h *Handle) SetLogger(l logging.Logger) {
+	h.log = l
+}
+
+// Shutdown 停止接受新的请求并等待进行中的请求完成，ctx结束时返回ctx.Err()
+func (h *Handle) Shutdown(ctx context.Context) error {
+	return h.inflight.Shutdown(ctx)
+}
+
+// Close 关闭工具化服务处理，等待进行中的请求完成
+func (h *Handle) Close() error {
+	return h.Shutdown(context.Background())
+}
+
+// WithContext 返回使用ctx的工具化服务处理副本，副本发起的请求以ctx中的span为父span，
+// 并与原处理共享配置和客户端
+func (h *Handle) WithContext(ctx context.Context) *Handle {
+	nh := new(Handle)
+	*nh = *h
+	nh.ctx = ctx
+	return nh
+}
+
+func (h *Handle) context() context.Context {
+	if h.ctx != nil {
+		return h.ctx
+	}
+	return context.Background()
+}
+
+// HealthCheck 检查工具化服务是否可用
+func (h *Handle) HealthCheck(ctx context.Context) error {
+	return utils.CheckHTTP(ctx, h.cli, h.cfg.HTTPAddr)
+}
+
+// ConvertHTMLToPDF html转换为pdf
+func (h *Handle) ConvertHTMLToPDF(src []byte) ([]byte, error) {
+	if err := h.inflight.Acquire(); err != nil {
+		return nil, err
+	}
+	defer h.inflight.Release()
+
+	if len(h.cfg.HTTPAddr) == 0 {
+		return nil, nil
+	}
+
+	start := time.Now()
+	pdfData, err := h.convertHTMLToPDF(src)
+	metrics.ObserveRequest(metrics.ComponentATS, "pdf", metrics.ErrorCode(err), start)
+	metrics.ObserveSize(metrics.ComponentATS, "html", len(src))
+	if err == nil {
+		metrics.ObserveSize(metrics.ComponentATS, "pdf", len(pdfData))
+	}
+
+	fields := []interface{}{
+		logging.KeyComponent, "ats",
+		logging.KeyDuration, time.Since(start),
+		"html_size", len(src),
+	}
+	if err != nil {
+		logging.Or(h.log).Error("ats convert html to pdf failed", append(fields, logging.KeyError, redact.String(err.Error()))...)
+	} else {
+		logging.Or(h.log).Debug("ats convert html to pdf", append(fields, "pdf_size", len(pdfData))...)
+	}
+	return pdfData, err
+}
+
+func (h *Handle) convertHTMLToPDF(src []byte) ([]byte, error) {
+	addr := h.cfg.HTTPAddr
+	if addr[len(addr)-1] == '/' {
+		addr = addr[:len(addr)-1]
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/pdf", addr), bytes.NewBuffer(src))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(h.context(), time.Second*60)
+	defer cancel()
+
+	var pdfData []byte
+	err = utils.Do(ctx, h.cli, req, func(resp *http.Response, err error) error {
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		buf, err := ioutil.ReadAll(zr)
+		if err != nil {
+			return err
+		}
+		pdfData = buf
+
+		zr.Close()
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pdfData, nil
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/antlinker/sdk/asapi"
+)
+
+var errUsage = errors.New("参数错误")
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return fs
+}
+
+// parseArgs 解析命令参数，并检查位置参数的数量
+func parseArgs(fs *flag.FlagSet, args []string, min int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() < min {
+		fs.Usage()
+		return nil, errUsage
+	}
+	return fs.Args(), nil
+}
+
+func cmdToken(ah *asapi.AuthorizeHandle, args []string) (interface{}, error) {
+	fs := newFlagSet("token")
+	force := fs.Bool("force", false, "强制获取新的令牌")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+
+	get := ah.GetToken
+	if *force {
+		get = ah.ForceGetToken
+	}
+	token, result := get()
+	if result != nil {
+		return nil, result
+	}
+	return map[string]string{"AccessToken": token}, nil
+}
+
+func cmdVerify(ah *asapi.AuthorizeHandle, args []string) (interface{}, error) {
+	fs := newFlagSet("verify")
+	v2 := fs.Bool("v2", false, "使用 /oauth2/verify/v2 验证")
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if *v2 {
+		info, result := ah.VerifyTokenV2(args[0])
+		if result != nil {
+			return nil, result
+		}
+		return info, nil
+	}
+
+	userID, clientID, result := ah.VerifyToken(args[0])
+	if result != nil {
+		return nil, result
+	}
+	return map[string]string{"UserID": userID, "ClientID": clientID}, nil
+}
+
+func cmdUserGet(ah *asapi.AuthorizeHandle, args []string) (interface{}, error) {
+	args, err := parseArgs(newFlagSet("user-get"), args, 1)
+	if err != nil {
+		return nil, err
+	}
+	info, result := ah.GetUser(args[0])
+	if result != nil {
+		return nil, result
+	}
+	return info, nil
+}
+
+func cmdUserAdd(ah *asapi.AuthorizeHandle, args []string) (interface{}, error) {
+	var req asapi.AddStaffUserRequest
+	fs := newFlagSet("user-add")
+	fs.StringVar(&req.UID, "uid", "", "用户ID")
+	fs.StringVar(&req.MobilePhone, "phone", "", "手机号")
+	fs.StringVar(&req.UserCode, "code", "", "学号")
+	fs.StringVar(&req.IDCard, "idcard", "", "身份证号")
+	fs.StringVar(&req.Password, "password", "", "密码")
+	fs.StringVar(&req.University, "university", "", "学校ID")
+	fs.StringVar(&req.Name, "name", "", "真实姓名")
+	fs.Var(&req.Sex, "sex", "性别（F女,M男）")
+	fs.StringVar(&req.DeptID, "dept", "", "部门或学院ID")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+	if req.UID == "" {
+		fs.Usage()
+		return nil, errUsage
+	}
+
+	if result := ah.AddStaffUser(&req); result != nil {
+		return nil, result
+	}
+	return ok(req.UID), nil
+}
+
+func cmdUserEdit(ah *asapi.AuthorizeHandle, args []string) (interface{}, error) {
+	var (
+		uid string
+		req asapi.AuthorizeEditUserRequest
+	)
+	fs := newFlagSet("user-edit")
+	fs.StringVar(&uid, "uid", "", "用户ID")
+	fs.StringVar(&req.MobilePhone, "phone", "", "手机号")
+	fs.StringVar(&req.UserCode, "code", "", "学号")
+	fs.StringVar(&req.IDCard, "idcard", "", "身份证号")
+	fs.StringVar(&req.University, "university", "", "学校ID")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+	if uid == "" {
+		fs.Usage()
+		return nil, errUsage
+	}
+
+	// 未指定的字段保持原值
+	info, result := ah.GetUser(uid)
+	if result != nil {
+		return nil, result
+	}
+	if req.MobilePhone == "" {
+		req.MobilePhone = info.MobilePhone
+	}
+	if req.UserCode == "" {
+		req.UserCode = info.UserCode
+	}
+	if req.IDCard == "" {
+		req.IDCard = info.IDCard
+	}
+	if req.University == "" {
+		req.University = info.University
+	}
+
+	if result := ah.EditUser(uid, &req); result != nil {
+		return nil, result
+	}
+	return ok(uid), nil
+}
+
+func cmdUserBasic(ah *asapi.AuthorizeHandle, args []string) (interface{}, error) {
+	var req asapi.UpdateUserBasicRequest
+	fs := newFlagSet("user-basic")
+	fs.StringVar(&req.UID, "uid", "", "用户ID")
+	fs.StringVar(&req.Name, "name", "", "真实姓名")
+	fs.StringVar(&req.DeptID, "dept", "", "部门或学院ID")
+	if _, err := parseArgs(fs, args, 0); err != nil {
+		return nil, err
+	}
+	if req.UID == "" {
+		fs.Usage()
+		return nil, errUsage
+	}
+
+	// 未指定的字段保持原值
+	if req.Name == "" || req.DeptID == "" {
+		info, result := ah.GetUserUpdate(req.UID)
+		if result != nil {
+			return nil, result
+		}
+		if req.Name == "" {
+			req.Name = info.RealName
+		}
+		if req.DeptID == "" {
+			req.DeptID = info.DeptID
+		}
+	}
+
+	if result := ah.UpdateUserBasic(&req); result != nil {
+		return nil, result
+	}
+	return ok(req.UID), nil
+}
+
+func cmdUserDel(ah *asapi.AuthorizeHandle, args []string) (interface{}, error) {
+	fs := newFlagSet("user-del")
+	staff := fs.Bool("staff", false, "删除学工用户")
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	del := ah.DelUser
+	if *staff {
+		del = ah.DelStaffUser
+	}
+	if result := del(args[0]); result != nil {
+		return nil, result
+	}
+	return ok(args[0]), nil
+}
+
+func cmdUserVersion(ah *asapi.AuthorizeHandle, args []string) (interface{}, error) {
+	args, err := parseArgs(newFlagSet("user-version"), args, 1)
+	if err != nil {
+		return nil, err
+	}
+	info, result := ah.GetUserVersion(args[0])
+	if result != nil {
+		return nil, result
+	}
+	return info, nil
+}
+
+func cmdAntUID(ah *asapi.AuthorizeHandle, args []string) (interface{}, error) {
+	fs := newFlagSet("antuid")
+	service := fs.String("service", "", "服务标识（默认使用配置中的服务标识）")
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+	auids, result := ah.GetAntUIDList(*service, args...)
+	if result != nil {
+		return nil, result
+	}
+	return auids, nil
+}
+
+func cmdAntUIDByUniversity(ah *asapi.AuthorizeHandle, args []string) (interface{}, error) {
+	args, err := parseArgs(newFlagSet("antuid-university"), args, 2)
+	if err != nil {
+		return nil, err
+	}
+	uid, result := ah.GetAntUIDByUniversity(args[0], args[1])
+	if result != nil {
+		return nil, result
+	}
+	return map[string]string{"UID": uid}, nil
+}
+
+func cmdStaffParam(ah *asapi.AuthorizeHandle, args []string) (interface{}, error) {
+	fs := newFlagSet("staff-param")
+	identify := fs.String("identify", "", "服务标识")
+	args, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if *identify == "" {
+		info, result := ah.GetAntStaffParam(args[0])
+		if result != nil {
+			return nil, result
+		}
+		return info, nil
+	}
+
+	buID, addr, result := ah.GetStaffParam(*identify, args[0])
+	if result != nil {
+		return nil, result
+	}
+	return map[string]string{"BuID": buID, "Addr": addr}, nil
+}
+
+func ok(uid string) map[string]string {
+	return map[string]string{"UID": uid, "Result": "ok"}
+}
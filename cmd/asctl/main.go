@@ -0,0 +1,196 @@
+// asctl 授权服务命令行客户端，用于排查线上问题
+//
+//	asctl [全局参数] <命令> [参数]
+//
+// 配置参数的读取顺序（后者覆盖前者）：-config指定的配置文件、ANTSDK_ASAPI_*环境变量、命令行参数。
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/config"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(ah *asapi.AuthorizeHandle, args []string) (interface{}, error)
+}
+
+var commands = []*command{
+	{"token", "[-force] 获取客户端访问令牌", cmdToken},
+	{"verify", "[-v2] <token> 验证访问令牌", cmdVerify},
+	{"user-get", "<uid> 查询用户信息", cmdUserGet},
+	{"user-add", "-uid <uid> [-phone -code -idcard -password -university -name -sex -dept] 增加学工用户", cmdUserAdd},
+	{"user-edit", "-uid <uid> [-phone -code -idcard -university] 编辑用户信息", cmdUserEdit},
+	{"user-basic", "-uid <uid> [-name -dept] 更新用户基础信息", cmdUserBasic},
+	{"user-del", "[-staff] <uid> 删除用户", cmdUserDel},
+	{"user-version", "<uid> 查询用户版本信息", cmdUserVersion},
+	{"antuid", "[-service <identify>] <uid>... 查询ANT用户ID", cmdAntUID},
+	{"antuid-university", "<userID> <university> 根据学校查询ANT用户ID", cmdAntUIDByUniversity},
+	{"staff-param", "[-identify <identify>] <uid> 查询学工参数（identify为空时查询ANT用户学工参数）", cmdStaffParam},
+}
+
+func main() {
+	var (
+		configFile = flag.String("config", "", "配置文件(.json、.yaml或.toml)")
+		output     = flag.String("o", "json", "输出格式：json或table")
+		cfg        asapi.Config
+	)
+	flag.StringVar(&cfg.ASURL, "url", "", "授权服务URL")
+	flag.StringVar(&cfg.ClientID, "client-id", "", "客户端ID")
+	flag.StringVar(&cfg.ClientSecret, "client-secret", "", "客户端秘钥")
+	flag.StringVar(&cfg.ServiceIdentify, "service", "", "服务标识")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	config, err := loadConfig(*configFile, &cfg)
+	if err != nil {
+		fatal(err)
+	}
+
+	name := flag.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		v, err := cmd.run(asapi.NewAuthorizeHandle(config), flag.Args()[1:])
+		if err != nil {
+			fatal(err)
+		}
+		if err := write(v, *output); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "asctl: 未知的命令 %s\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "用法: asctl [全局参数] <命令> [参数]")
+	fmt.Fprintln(os.Stderr, "\n命令:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintln(os.Stderr, "\n全局参数:")
+	flag.PrintDefaults()
+}
+
+// loadConfig 依次从配置文件、环境变量、命令行参数读取配置
+func loadConfig(name string, flags *asapi.Config) (*asapi.Config, error) {
+	l := new(config.Loader)
+	if name != "" {
+		l.Files = []string{name}
+	}
+	c, err := l.Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := c.ASAPI
+	if cfg == nil {
+		cfg = new(asapi.Config)
+	}
+	for _, item := range []struct {
+		field *string
+		flag  string
+	}{
+		{&cfg.ASURL, flags.ASURL},
+		{&cfg.ClientID, flags.ClientID},
+		{&cfg.ClientSecret, flags.ClientSecret},
+		{&cfg.ServiceIdentify, flags.ServiceIdentify},
+	} {
+		if item.flag != "" {
+			*item.field = item.flag
+		}
+	}
+
+	if cfg.ASURL == "" {
+		return nil, fmt.Errorf("未指定授权服务URL")
+	}
+	return cfg, nil
+}
+
+func write(v interface{}, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "table":
+		return printTable(v)
+	}
+	return fmt.Errorf("未知的输出格式 %s", format)
+}
+
+// printTable 以表格形式输出：对象输出为字段、值两列，数组每个元素输出为一行
+func printTable(v interface{}) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var data interface{}
+	if err := json.Unmarshal(buf, &data); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	switch d := data.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintln(w, "FIELD\tVALUE")
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s\t%s\n", k, cell(d[k]))
+		}
+	case []interface{}:
+		for _, item := range d {
+			fmt.Fprintln(w, cell(item))
+		}
+	default:
+		fmt.Fprintln(w, cell(d))
+	}
+	return w.Flush()
+}
+
+func cell(v interface{}) string {
+	switch d := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return d
+	case []interface{}:
+		items := make([]string, len(d))
+		for i, item := range d {
+			items[i] = cell(item)
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		buf, _ := json.Marshal(d)
+		return string(buf)
+	}
+	return fmt.Sprint(v)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "asctl:", err)
+	os.Exit(1)
+}
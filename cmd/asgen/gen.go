@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+)
+
+const header = "// Code generated by asgen. DO NOT EDIT.\n\n"
+
+var apiTemplate = template.Must(template.New("api").Parse(`package {{.Package}}
+{{range .Routes}}{{if not .Manual}}{{$r := .}}{{with .Request}}{{if .Fields}}
+// {{$r.Name}}Request {{$r.Doc}}请求参数
+type {{$r.Name}}Request struct {
+{{range .Fields}}	{{.Name}} {{.Type}}{{if .Doc}} // {{.Doc}}{{end}}
+{{end}}}
+{{end}}{{end}}{{with .Response}}{{if .Fields}}
+// {{$r.Name}}Result {{$r.Doc}}响应
+type {{$r.Name}}Result struct {
+{{range .Fields}}	{{.Name}} {{.Type}}{{if .Doc}} // {{.Doc}}{{end}}
+{{end}}}
+{{end}}{{end}}
+// {{.Name}} {{.Doc}}
+func (ah *AuthorizeHandle) {{.Name}}({{if .RequestType}}req *{{.RequestType}}{{end}}) {{if .ResponseType}}(*{{.ResponseType}}, *ErrorResult) {
+	return call[{{.ResponseType}}](ah, "{{.Router}}", {{if .RequestType}}req{{else}}struct{}{}{{end}})
+}{{else}}(result *ErrorResult) {
+	_, result = call[struct{}](ah, "{{.Router}}", {{if .RequestType}}req{{else}}struct{}{}{{end}})
+	return
+}{{end}}
+
+// {{.Name}} {{.Doc}}
+func {{.Name}}({{if .RequestType}}req *{{.RequestType}}{{end}}) {{if .ResponseType}}(*{{.ResponseType}}, *ErrorResult){{else}}*ErrorResult{{end}} {
+	return gAuthorize.{{.Name}}({{if .RequestType}}req{{end}})
+}
+{{end}}{{end}}`))
+
+var fakeTemplate = template.Must(template.New("fake").Funcs(template.FuncMap{
+	"qualify": qualify,
+}).Parse(`package fakeas
+
+import (
+	"net/http"
+
+	"github.com/antlinker/sdk/asapi"
+)
+
+// Handlers 模拟授权服务的接口处理，未设置的接口返回404
+type Handlers struct {
+{{range .Routes}}	{{.Name}} func(req {{qualify .RequestType}}) {{if .ResponseType}}({{qualify .ResponseType}}, *asapi.ErrorResult){{else}}*asapi.ErrorResult{{end}} // {{.Router}} {{.Doc}}
+{{end}}}
+
+// register 注册已设置的接口处理
+func (h *Handlers) register(s *Server, mux *http.ServeMux) {
+{{range .Routes}}	if h.{{.Name}} != nil {
+		mux.Handle("{{.Router}}", {{if .ResponseType}}handle(s, h.{{.Name}}){{else}}handleNoResult(s, h.{{.Name}}){{end}})
+	}
+{{end}}}
+`))
+
+// GenerateAPI 生成asapi的代码，没有需要生成的接口时返回nil
+func GenerateAPI(spec *Spec) ([]byte, error) {
+	var n int
+	for _, r := range spec.Routes {
+		if !r.Manual {
+			n++
+		}
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	return execute(apiTemplate, spec)
+}
+
+// GenerateFake 生成fakeas模拟服务的代码
+func GenerateFake(spec *Spec) ([]byte, error) {
+	if len(spec.Routes) == 0 {
+		return nil, nil
+	}
+	return execute(fakeTemplate, spec)
+}
+
+func execute(t *template.Template, spec *Spec) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	if err := t.Execute(&buf, spec); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("格式化生成的代码失败：%s\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+// qualify 返回模拟服务中使用的类型，asapi中的类型使用指针并加上包名，未指定类型时使用map
+func qualify(typ string) string {
+	switch {
+	case typ == "":
+		return "map[string]interface{}"
+	case strings.ContainsAny(typ, ".[]{}*"):
+		return typ
+	}
+	return "*asapi." + typ
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "更新testdata中的生成结果")
+
+func TestGenerate(t *testing.T) {
+	spec, err := LoadSpec("testdata/api.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, gen := range map[string]func(*Spec) ([]byte, error){
+		"api.golden":  GenerateAPI,
+		"fake.golden": GenerateFake,
+	} {
+		src, err := gen(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		golden := filepath.Join("testdata", name)
+		if *update {
+			if err := ioutil.WriteFile(golden, src, 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(src, want) {
+			t.Errorf("%s mismatch, run go test -update\n%s", name, src)
+		}
+	}
+}
+
+func TestSpecValidate(t *testing.T) {
+	for _, spec := range []*Spec{
+		{Routes: []*Route{{Name: "getDept", Router: "/api/authorize/getdept"}}},
+		{Routes: []*Route{{Name: "GetDept", Router: "api/authorize/getdept"}}},
+		{Routes: []*Route{{Name: "GetDept", Router: "/a"}, {Name: "GetDept", Router: "/b"}}},
+		{Routes: []*Route{{Name: "GetDept", Router: "/a", Request: &Message{Type: "X", Fields: []*Field{{Name: "UID", Type: "string"}}}}}},
+	} {
+		if err := spec.Validate(); err == nil {
+			t.Errorf("expected error for %+v", spec.Routes[0])
+		}
+	}
+}
+
+func TestGenerateEmpty(t *testing.T) {
+	src, err := GenerateAPI(&Spec{Package: "asapi", Routes: []*Route{{Name: "GetUser", Router: "/a", Manual: true}}})
+	if err != nil || src != nil {
+		t.Fatalf("unexpected result: %s, %v", src, err)
+	}
+}
+
+// TestGeneratedUpToDate 检查asapi中生成的代码与api.yaml一致，不一致时在asapi目录执行go generate
+func TestGeneratedUpToDate(t *testing.T) {
+	spec, err := LoadSpec("../../asapi/api.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, gen := range map[string]func(*Spec) ([]byte, error){
+		"../../asapi/zz_generated.go":        GenerateAPI,
+		"../../asapi/fakeas/zz_generated.go": GenerateFake,
+	} {
+		src, err := gen(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(src, want) {
+			t.Errorf("%s is out of date, run go generate in asapi", name)
+		}
+	}
+}
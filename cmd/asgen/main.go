@@ -0,0 +1,62 @@
+// asgen 根据授权服务接口描述文件生成asapi的请求、响应类型，AuthorizeHandle方法，
+// 包级别的函数和fakeas模拟服务的接口处理，在asapi中通过go:generate调用：
+//
+//	//go:generate go run ../cmd/asgen -spec api.yaml -o zz_generated.go -fake fakeas/zz_generated.go
+//
+// 描述文件的格式参考 testdata/api.yaml。
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	var (
+		specFile = flag.String("spec", "api.yaml", "接口描述文件(.yaml)")
+		output   = flag.String("o", "zz_generated.go", "生成的asapi代码文件")
+		fake     = flag.String("fake", "", "生成的模拟服务代码文件，为空时不生成")
+	)
+	flag.Parse()
+
+	spec, err := LoadSpec(*specFile)
+	if err != nil {
+		fatal(err)
+	}
+
+	src, err := GenerateAPI(spec)
+	if err != nil {
+		fatal(err)
+	}
+	if err := writeFile(*output, src); err != nil {
+		fatal(err)
+	}
+
+	if *fake != "" {
+		src, err := GenerateFake(spec)
+		if err != nil {
+			fatal(err)
+		}
+		if err := writeFile(*fake, src); err != nil {
+			fatal(err)
+		}
+	}
+}
+
+// writeFile 写入生成的代码，没有需要生成的代码时删除原有的文件
+func writeFile(name string, src []byte) error {
+	if src == nil {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return ioutil.WriteFile(name, src, 0644)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "asgen:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Spec 接口描述
+type Spec struct {
+	Package string   `yaml:"package"` // 生成代码的包名，默认asapi
+	Routes  []*Route `yaml:"routes"`
+}
+
+// Route 授权服务接口
+type Route struct {
+	Name     string   `yaml:"name"`     // 方法名
+	Router   string   `yaml:"router"`   // 接口路由，如 /api/authorize/getuser
+	Doc      string   `yaml:"doc"`      // 方法说明
+	Manual   bool     `yaml:"manual"`   // 方法已手工实现，只生成模拟服务的接口处理
+	Request  *Message `yaml:"request"`  // 请求参数
+	Response *Message `yaml:"response"` // 响应内容，为空表示接口没有响应内容
+}
+
+// Message 请求参数或响应内容，指定Type时使用已有的类型，否则根据Fields生成类型
+type Message struct {
+	Type   string   `yaml:"type"`
+	Fields []*Field `yaml:"fields"`
+}
+
+// Field 字段
+type Field struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	Doc  string `yaml:"doc"`
+}
+
+// LoadSpec 读取接口描述文件
+func LoadSpec(name string) (*Spec, error) {
+	buf, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var spec Spec
+	if err := yaml.UnmarshalStrict(buf, &spec); err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	return &spec, nil
+}
+
+// Validate 检查接口描述
+func (s *Spec) Validate() error {
+	if s.Package == "" {
+		s.Package = "asapi"
+	}
+	names := make(map[string]bool)
+	for i, r := range s.Routes {
+		switch {
+		case !token.IsExported(r.Name):
+			return fmt.Errorf("第%d个接口的名称(name)无效：%q", i+1, r.Name)
+		case names[r.Name]:
+			return fmt.Errorf("接口%s重复", r.Name)
+		case !strings.HasPrefix(r.Router, "/"):
+			return fmt.Errorf("接口%s的路由(router)必须以/开头", r.Name)
+		}
+		names[r.Name] = true
+
+		for _, m := range []*Message{r.Request, r.Response} {
+			if m == nil {
+				continue
+			}
+			if m.Type != "" && len(m.Fields) > 0 {
+				return fmt.Errorf("接口%s的type和fields不能同时指定", r.Name)
+			}
+			for _, f := range m.Fields {
+				if !token.IsExported(f.Name) || f.Type == "" {
+					return fmt.Errorf("接口%s的字段%q无效", r.Name, f.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// RequestType 请求参数的类型名，没有请求参数时为空
+func (r *Route) RequestType() string {
+	switch {
+	case r.Request == nil:
+		return ""
+	case r.Request.Type != "":
+		return r.Request.Type
+	case len(r.Request.Fields) > 0:
+		return r.Name + "Request"
+	}
+	return ""
+}
+
+// ResponseType 响应内容的类型名，没有响应内容时为空
+func (r *Route) ResponseType() string {
+	switch {
+	case r.Response == nil:
+		return ""
+	case r.Response.Type != "":
+		return r.Response.Type
+	case len(r.Response.Fields) > 0:
+		return r.Name + "Result"
+	}
+	return ""
+}
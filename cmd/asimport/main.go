@@ -0,0 +1,129 @@
+// asimport 将CSV/XLSX格式的学工、学生花名册导入授权服务
+//
+//	asimport -config sdk.yaml -file roster.xlsx -map "UID=工号,Name=姓名,MobilePhone=手机号"
+//
+// 默认只输出与授权服务的比对结果，指定 -apply 后才会执行新增、更新和删除操作。
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/config"
+	"github.com/antlinker/sdk/roster"
+)
+
+func main() {
+	var (
+		configFile  = flag.String("config", "", "配置文件(.json、.yaml或.toml)")
+		file        = flag.String("file", "", "花名册文件(.csv或.xlsx)")
+		mapping     = flag.String("map", "", "字段与列名的映射，如 UID=工号,Name=姓名")
+		apply       = flag.Bool("apply", false, "执行变更（默认只输出比对结果）")
+		concurrency = flag.Int("c", 10, "并发请求数量")
+		reportFile  = flag.String("report", "", "将执行结果写入CSV文件")
+	)
+	flag.Parse()
+
+	if *configFile == "" || *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := readConfig(*configFile)
+	if err != nil {
+		fatal(err)
+	}
+	m, err := roster.ParseMapping(*mapping)
+	if err != nil {
+		fatal(err)
+	}
+	records, err := roster.ReadFile(*file, m)
+	if err != nil {
+		fatal(err)
+	}
+
+	ctx := context.Background()
+	ah := asapi.NewAuthorizeHandle(cfg)
+	changes := roster.Diff(ctx, ah, records, *concurrency)
+
+	if *apply {
+		report := roster.Apply(ctx, ah, changes, *concurrency)
+		fmt.Printf("新增：%d 更新：%d 删除：%d 未变化：%d 失败：%d\n",
+			report.Created, report.Updated, report.Deleted, report.Unchanged, report.Failed)
+	}
+
+	printChanges(changes)
+
+	if *reportFile != "" {
+		if err := writeReport(*reportFile, changes, *apply); err != nil {
+			fatal(err)
+		}
+	}
+}
+
+func readConfig(name string) (*asapi.Config, error) {
+	cfg, err := config.Load(name)
+	if err != nil {
+		return nil, err
+	} else if cfg.ASAPI == nil {
+		return nil, fmt.Errorf("配置文件中缺少授权服务(ASAPI)配置")
+	}
+	return cfg.ASAPI, nil
+}
+
+func describe(c *roster.Change) string {
+	if c.Err != nil {
+		return c.Err.Error()
+	}
+	var items []string
+	for _, f := range c.Fields {
+		items = append(items, fmt.Sprintf("%s: %q -> %q", f.Field, f.Old, f.New))
+	}
+	return strings.Join(items, ", ")
+}
+
+func printChanges(changes []*roster.Change) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "LINE\tUID\tOP\tDETAIL")
+	for _, c := range changes {
+		if c.Op == roster.OpNone && c.Err == nil {
+			continue
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", c.Record.Line, c.Record.Request.UID, c.Op, describe(c))
+	}
+	w.Flush()
+}
+
+func writeReport(name string, changes []*roster.Change, applied bool) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"Line", "UID", "Op", "Applied", "Success", "Detail"})
+	for _, c := range changes {
+		w.Write([]string{
+			fmt.Sprint(c.Record.Line),
+			c.Record.Request.UID,
+			string(c.Op),
+			fmt.Sprint(applied),
+			fmt.Sprint(c.Err == nil),
+			describe(c),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "asimport:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/ats"
+	"github.com/antlinker/sdk/job"
+	"github.com/antlinker/sdk/permission/client"
+	"github.com/antlinker/sdk/plan"
+	"github.com/antlinker/sdk/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultEnvPrefix 默认的环境变量前缀
+const DefaultEnvPrefix = "ANTSDK"
+
+// Config SDK配置参数，为nil的部分表示未配置
+type Config struct {
+	ASAPI         *asapi.Config     // 授权服务
+	RouterExpires map[string]int64  // 授权服务接口缓存时间(单位秒)
+	Job           *job.Config       // 作业任务
+	Plan          *plan.Config      // 计划任务
+	ATS           *ats.Config       // 工具化服务
+	MQTT          *MQTTConfig       // MQTT
+	Permission    *client.RPCConfig // 权限中心
+}
+
+// MQTTConfig MQTT配置参数
+type MQTTConfig struct {
+	utils.MQTTConfig
+	Password string // 密码，不为空时用于生成PasswordHandler
+}
+
+// Client 转换为创建MQTT客户端的配置参数
+func (c *MQTTConfig) Client() *utils.MQTTConfig {
+	cfg := c.MQTTConfig
+	if password := c.Password; password != "" && cfg.PasswordHandler == nil {
+		cfg.PasswordHandler = func() string { return password }
+	}
+	return &cfg
+}
+
+// Validate 检查已配置部分的必填参数
+func (c *Config) Validate() error {
+	if c.ASAPI != nil {
+		if err := c.ASAPI.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Job != nil && c.Job.HTTPAddr == "" {
+		return errors.New("job: 未指定服务地址(HTTPAddr)")
+	}
+	if c.Plan != nil && c.Plan.HTTPAddr == "" {
+		return errors.New("plan: 未指定服务地址(HTTPAddr)")
+	}
+	if c.ATS != nil && c.ATS.HTTPAddr == "" {
+		return errors.New("ats: 未指定服务地址(HTTPAddr)")
+	}
+	if c.MQTT != nil && c.MQTT.BrokerAddress == "" {
+		return errors.New("mqtt: 未指定服务地址(BrokerAddress)")
+	}
+	return nil
+}
+
+// ApplyTo 将可热更新的参数应用到运行中的授权处理：客户端ID和秘钥、接口缓存时间(RouterExpires)
+// 其他参数（包括缓存gc间隔CacheGCInterval、是否启用缓存等）只在创建授权处理时生效，修改后需要重启，
+// 可以使用RestartRequired检查
+func (c *Config) ApplyTo(ah *asapi.AuthorizeHandle) {
+	if c.ASAPI != nil {
+		clientID, clientSecret := ah.Credentials()
+		if c.ASAPI.ClientID != clientID || c.ASAPI.ClientSecret != clientSecret {
+			ah.SetCredentials(c.ASAPI.ClientID, c.ASAPI.ClientSecret)
+		}
+	}
+	if len(c.RouterExpires) > 0 {
+		asapi.SetRouterExpires(c.RouterExpires)
+	}
+}
+
+// RestartRequired 返回与prev相比发生变化、但不能通过ApplyTo热更新的参数，
+// 如 ASAPI.CacheGCInterval、Job，为空表示所有变化都可以热更新
+func (c *Config) RestartRequired(prev *Config) (fields []string) {
+	if prev == nil {
+		prev = new(Config)
+	}
+	a, b := reflect.ValueOf(c.ASAPI), reflect.ValueOf(prev.ASAPI)
+	switch {
+	case a.IsNil() != b.IsNil():
+		fields = append(fields, "ASAPI")
+	case !a.IsNil():
+		t := a.Elem().Type()
+		for i := 0; i < t.NumField(); i++ {
+			name := t.Field(i).Name
+			if name == "ClientID" || name == "ClientSecret" {
+				continue
+			}
+			if !reflect.DeepEqual(a.Elem().Field(i).Interface(), b.Elem().Field(i).Interface()) {
+				fields = append(fields, "ASAPI."+name)
+			}
+		}
+	}
+
+	for name, v := range map[string][2]interface{}{
+		"Job":        {c.Job, prev.Job},
+		"Plan":       {c.Plan, prev.Plan},
+		"ATS":        {c.ATS, prev.ATS},
+		"MQTT":       {c.MQTT, prev.MQTT},
+		"Permission": {c.Permission, prev.Permission},
+	} {
+		if !reflect.DeepEqual(v[0], v[1]) {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return
+}
+
+// Loader 配置加载
+// 依次读取配置文件（后面的文件覆盖前面的），再读取环境变量，
+// 配置文件中的键名与字段名相同，不区分大小写
+type Loader struct {
+	Files     []string // 配置文件，支持.json、.yaml、.yml、.toml
+	EnvPrefix string   // 环境变量前缀，默认为ANTSDK，为"-"时不读取环境变量
+}
+
+// Load 从配置文件和环境变量中加载配置
+func Load(files ...string) (*Config, error) {
+	l := &Loader{Files: files}
+	return l.Load()
+}
+
+// Load 加载并检查配置
+func (l *Loader) Load() (*Config, error) {
+	cfg, err := l.Decode()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Decode 从配置文件和环境变量中加载配置，不检查必填参数
+func (l *Loader) Decode() (*Config, error) {
+	merged := make(map[string]interface{})
+	for _, name := range l.Files {
+		m, err := readFile(name)
+		if err != nil {
+			return nil, err
+		}
+		merge(merged, m)
+	}
+
+	buf, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(buf, &cfg); err != nil {
+		return nil, err
+	}
+
+	prefix := l.EnvPrefix
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+	if prefix != "-" {
+		if err := loadEnv(&cfg, prefix); err != nil {
+			return nil, err
+		}
+	}
+	return &cfg, nil
+}
+
+// readFile 根据扩展名读取配置文件
+func readFile(name string) (map[string]interface{}, error) {
+	buf, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(buf))
+		dec.UseNumber()
+		err = dec.Decode(&m)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(buf, &m)
+	case ".toml":
+		err = toml.Unmarshal(buf, &m)
+	default:
+		return nil, fmt.Errorf("config: 不支持的配置文件格式 %s", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: 读取%s发生错误：%s", name, err)
+	}
+	return m, nil
+}
+
+// merge 将src合并到dst中，键名统一转换为小写
+func merge(dst, src map[string]interface{}) {
+	for k, v := range src {
+		k = strings.ToLower(k)
+		if sm, ok := v.(map[string]interface{}); ok {
+			dm, ok := dst[k].(map[string]interface{})
+			if !ok {
+				dm = make(map[string]interface{})
+				dst[k] = dm
+			}
+			merge(dm, sm)
+			continue
+		}
+		dst[k] = v
+	}
+}
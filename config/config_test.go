@@ -0,0 +1,111 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/job"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	name = filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestLoad(t *testing.T) {
+	yamlFile := writeFile(t, "sdk.yaml", `
+asapi:
+  asurl: http://127.0.0.1:8099
+  clientid: client
+  clientsecret: secret
+  isenabledcache: true
+routerexpires:
+  /api/authorize/usercode: 120
+mqtt:
+  brokeraddress: tcp://127.0.0.1:1883
+  password: mqtt
+`)
+	tomlFile := writeFile(t, "override.toml", `
+[ASAPI]
+ServiceIdentify = "TEST"
+`)
+
+	os.Setenv("ANTSDK_ASAPI_CLIENTSECRET", "env-secret")
+	os.Setenv("ANTSDK_JOB_HTTPADDR", "http://127.0.0.1:3300")
+	os.Setenv("ANTSDK_ASAPI_ASURLS", "http://127.0.0.1:8100, http://127.0.0.1:8101")
+	defer os.Unsetenv("ANTSDK_ASAPI_CLIENTSECRET")
+	defer os.Unsetenv("ANTSDK_ASAPI_ASURLS")
+	defer os.Unsetenv("ANTSDK_JOB_HTTPADDR")
+
+	cfg, err := Load(yamlFile, tomlFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := cfg.ASAPI; a.ASURL != "http://127.0.0.1:8099" || a.ServiceIdentify != "TEST" ||
+		a.ClientSecret != "env-secret" || !a.IsEnabledCache || len(a.ASURLs) != 2 || a.ASURLs[1] != "http://127.0.0.1:8101" {
+		t.Fatalf("unexpected asapi config: %+v", a)
+	}
+	if cfg.RouterExpires["/api/authorize/usercode"] != 120 {
+		t.Fatalf("unexpected router expires: %v", cfg.RouterExpires)
+	}
+	if cfg.Job == nil || cfg.Job.HTTPAddr != "http://127.0.0.1:3300" {
+		t.Fatalf("unexpected job config: %+v", cfg.Job)
+	}
+	if cfg.Plan != nil {
+		t.Fatalf("plan should not be configured: %+v", cfg.Plan)
+	}
+	if h := cfg.MQTT.Client().PasswordHandler; h == nil || h() != "mqtt" {
+		t.Fatal("unexpected mqtt password handler")
+	}
+}
+
+func TestLoadValidate(t *testing.T) {
+	name := writeFile(t, "sdk.json", `{"ASAPI":{"ClientID":"client"}}`)
+	if _, err := Load(name); err == nil {
+		t.Fatal("expected missing ASURL error")
+	}
+}
+
+func TestApplyTo(t *testing.T) {
+	ah := asapi.NewAuthorizeHandle(&asapi.Config{ASURL: "http://127.0.0.1:8099", ClientID: "old", ClientSecret: "old"})
+	defer ah.Close()
+
+	// 与SetCredentials并发执行时不能有数据竞争
+	done := make(chan struct{})
+	go func() {
+		ah.SetCredentials("other", "other")
+		close(done)
+	}()
+	cfg := &Config{ASAPI: &asapi.Config{ClientID: "new", ClientSecret: "secret"}}
+	cfg.ApplyTo(ah)
+	<-done
+
+	cfg.ApplyTo(ah)
+	if id, secret := ah.Credentials(); id != "new" || secret != "secret" {
+		t.Fatalf("unexpected credentials: %s, %s", id, secret)
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	prev := &Config{ASAPI: &asapi.Config{ASURL: "http://127.0.0.1:8099", ClientID: "old", CacheGCInterval: 60}}
+	cfg := &Config{
+		ASAPI:         &asapi.Config{ASURL: "http://127.0.0.1:8099", ClientID: "new", CacheGCInterval: 30},
+		RouterExpires: map[string]int64{"/api/authorize/getstaffparam": 10},
+	}
+	if fields := cfg.RestartRequired(prev); !reflect.DeepEqual(fields, []string{"ASAPI.CacheGCInterval"}) {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+
+	cfg.ASAPI.CacheGCInterval = 60
+	cfg.Job = &job.Config{}
+	if fields := cfg.RestartRequired(prev); !reflect.DeepEqual(fields, []string{"Job"}) {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// loadEnv 使用环境变量覆盖配置，变量名格式为 前缀_部分_字段（全部大写），
+// 如 ANTSDK_ASAPI_ASURL、ANTSDK_ASAPI_CLIENTSECRET、ANTSDK_MQTT_BROKERADDRESS
+func loadEnv(cfg *Config, prefix string) error {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := v.Field(i)
+		if f.Kind() != reflect.Ptr || f.Type().Elem().Kind() != reflect.Struct {
+			continue
+		}
+
+		section := prefix + "_" + strings.ToUpper(t.Field(i).Name) + "_"
+		if !hasEnvPrefix(section) {
+			continue
+		}
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+		if err := setFields(f.Elem(), section); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasEnvPrefix(prefix string) bool {
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func setFields(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		f := v.Field(i)
+		if sf.Anonymous && f.Kind() == reflect.Struct {
+			if err := setFields(f, prefix); err != nil {
+				return err
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		name := prefix + strings.ToUpper(sf.Name)
+		s, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		switch f.Kind() {
+		case reflect.String:
+			f.SetString(s)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return fmt.Errorf("config: 环境变量%s的值无效：%s", name, err)
+			}
+			f.SetBool(b)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("config: 环境变量%s的值无效：%s", name, err)
+			}
+			f.SetInt(n)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("config: 环境变量%s的值无效：%s", name, err)
+			}
+			f.SetUint(n)
+		case reflect.Slice:
+			if f.Type().Elem().Kind() != reflect.String {
+				continue
+			}
+			// 多个值以逗号分隔
+			var items []string
+			for _, item := range strings.Split(s, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			f.Set(reflect.ValueOf(items).Convert(f.Type()))
+		}
+	}
+	return nil
+}
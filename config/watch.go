@@ -0,0 +1,50 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Watch 定期检查配置文件，文件发生变化时重新加载配置并调用fn，直到ctx结束
+// 加载失败时cfg为nil，此时应继续使用原有的配置
+func (l *Loader) Watch(ctx context.Context, interval time.Duration, fn func(cfg *Config, err error)) {
+	if interval <= 0 {
+		interval = time.Second * 10
+	}
+
+	last := l.stat()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cur := l.stat()
+		if cur == last {
+			continue
+		}
+		last = cur
+		fn(l.Load())
+	}
+}
+
+// stat 返回所有配置文件的修改时间和大小组成的标识
+func (l *Loader) stat() string {
+	var buf bytes.Buffer
+	for _, name := range l.Files {
+		fi, err := os.Stat(name)
+		if err != nil {
+			buf.WriteString("-;")
+			continue
+		}
+		fmt.Fprintf(&buf, "%d:%d;", fi.ModTime().UnixNano(), fi.Size())
+	}
+	return buf.String()
+}
@@ -4,12 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/logging"
+	"github.com/antlinker/sdk/metrics"
+	"github.com/antlinker/sdk/redact"
 	"github.com/antlinker/sdk/utils"
 )
 
@@ -25,8 +26,9 @@ type Config struct {
 // NewHandle 创建作业任务
 func NewHandle(auh *asapi.AuthorizeHandle, config *Config) *Handle {
 	return &Handle{
-		auh: auh,
-		cfg: config,
+		auh:      auh,
+		cfg:      config,
+		inflight: new(utils.InFlight),
 	}
 }
 
@@ -34,6 +36,86 @@ func NewHandle(auh *asapi.AuthorizeHandle, config *Config) *Handle {
 type Handle struct {
 	auh *asapi.AuthorizeHandle
 	cfg *Config
+	cli *http.Client
+
+	inflight *utils.InFlight
+	ctx      context.Context // 由WithContext设置，用于链路追踪
+	log      logging.Logger
+}
+
+// SetHTTPClient 设置请求使用的HTTP客户端，未设置时每次请求创建新的客户端
+func (h *Handle) SetHTTPClient(cli *http.Client) {
+	h.cli = cli
+}
+
+// authorize 未设置授权处理时使用asapi.InitAPI初始化的授权处理
+func (h *Handle) authorize() *asapi.AuthorizeHandle {
+	if h.auh != nil {
+		return h.auh
+	}
+	return asapi.GetAuthorize()
+}
+
+// SetLogger 设置作业任务处理使用的日志，为nil时使用logging.Default()
+func (h *Handle) SetLogger(l logging.Logger) {
+	h.log = l
+}
+
+// logRequest 请求成功时输出Debug日志，失败时输出Error日志
+func (h *Handle) logRequest(typ string, start time.Time, data []byte, err error) {
+	fields := []interface{}{
+		logging.KeyComponent, "job",
+		logging.KeyType, typ,
+		logging.KeyDuration, time.Since(start),
+	}
+	var se *utils.StatusError
+	if errors.As(err, &se) {
+		fields = append(fields, logging.KeyStatus, se.Code)
+	}
+
+	l := logging.Or(h.log)
+	if err != nil {
+		if len(data) > 0 {
+			fields = append(fields, logging.KeyResponse, redact.String(string(data)))
+		}
+		l.Error("job request failed", append(fields, logging.KeyError, redact.String(err.Error()))...)
+		return
+	}
+	l.Debug("job request", fields...)
+}
+
+// Shutdown 停止接受新的请求并等待进行中的请求完成，ctx结束时返回ctx.Err()
+func (h *Handle) Shutdown(ctx context.Context) error {
+	return h.inflight.Shutdown(ctx)
+}
+
+// Close 关闭作业任务处理，等待进行中的请求完成
+func (h *Handle) Close() error {
+	return h.Shutdown(context.Background())
+}
+
+// WithContext 返回使用ctx的作业任务处理副本，副本发起的请求以ctx中的span为父span，
+// 并与原处理共享配置和客户端
+func (h *Handle) WithContext(ctx context.Context) *Handle {
+	nh := new(Handle)
+	*nh = *h
+	nh.ctx = ctx
+	if h.auh != nil {
+		nh.auh = h.auh.WithContext(ctx)
+	}
+	return nh
+}
+
+func (h *Handle) context() context.Context {
+	if h.ctx != nil {
+		return h.ctx
+	}
+	return context.Background()
+}
+
+// HealthCheck 检查作业任务是否可用
+func (h *Handle) HealthCheck(ctx context.Context) error {
+	return utils.CheckHTTP(ctx, h.cli, h.getURL(""))
 }
 
 func (h *Handle) getURL(router string) string {
@@ -63,12 +145,24 @@ func (h *Handle) getANTUserID(intelUserCode string) (userID string, err error) {
 }
 
 func (h *Handle) request(typ string, rdata interface{}) (err error) {
+	if err = h.inflight.Acquire(); err != nil {
+		return
+	}
+	defer h.inflight.Release()
+
+	var data []byte
+	start := time.Now()
+	defer func() {
+		metrics.ObserveRequest(metrics.ComponentJob, typ, metrics.ErrorCode(err), start)
+		h.logRequest(typ, start, data, err)
+	}()
+
 	buf, err := json.Marshal(rdata)
 	if err != nil {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	ctx, cancel := context.WithTimeout(h.context(), time.Second*5)
 
 	defer cancel()
 
@@ -77,8 +171,8 @@ func (h *Handle) request(typ string, rdata interface{}) (err error) {
 		"data": string(buf),
 	}
 
-	data, err := utils.PostJSON(ctx, h.getURL(jobRouter), body, func(req *http.Request) (*http.Request, error) {
-		token, err := h.auh.GetToken()
+	data, err = utils.PostJSONWithClient(ctx, h.cli, h.getURL(jobRouter), body, func(req *http.Request) (*http.Request, error) {
+		token, err := h.authorize().GetToken()
 		if err != nil {
 			return nil, err
 		}
@@ -86,16 +180,13 @@ func (h *Handle) request(typ string, rdata interface{}) (err error) {
 		return req, nil
 	})
 	if err != nil {
-		if len(data) > 0 {
-			log.Println("请求发生错误：", string(data))
-		}
 		return
 	}
 
 	var str string
 	json.Unmarshal(data, &str)
 	if str != "ok" {
-		err = fmt.Errorf(string(data))
+		err = errors.New(redact.String(string(data)))
 	}
 
 	return
@@ -0,0 +1,140 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/antlinker/sdk/utils"
+)
+
+// closer 可关闭的处理
+type closer interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Start 检查已配置的各服务是否可用，任一服务不可用时返回错误，用于在启动时尽早发现配置问题
+func (c *Client) Start(ctx context.Context) error {
+	result := c.HealthCheck(ctx)
+	names := make([]string, 0, len(result))
+	for name := range result {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := result[name]; err != nil {
+			return fmt.Errorf("sdk: %s不可用：%v", name, err)
+		}
+	}
+	return nil
+}
+
+// Shutdown 关闭SDK客户端：先等待各处理进行中的请求和正在发布的MQTT消息完成，
+// 再断开由New创建的MQTT客户端、关闭权限中心的RPC连接并释放授权处理的缓存和连接。
+// ctx结束时不再等待，仍会释放所有资源，并返回ctx.Err()
+func (c *Client) Shutdown(ctx context.Context) error {
+	var handles []closer
+	if c.todo != nil {
+		handles = append(handles, c.todo)
+	}
+	if c.welcome != nil {
+		handles = append(handles, c.welcome)
+	}
+	if c.job != nil {
+		handles = append(handles, c.job)
+	}
+	if c.plan != nil {
+		handles = append(handles, c.plan)
+	}
+	if c.ats != nil {
+		handles = append(handles, c.ats)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		firstErr error
+	)
+	setErr := func(err error) {
+		lock.Lock()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		lock.Unlock()
+	}
+
+	for _, h := range handles {
+		wg.Add(1)
+		go func(h closer) {
+			defer wg.Done()
+			setErr(h.Shutdown(ctx))
+		}(h)
+	}
+	wg.Wait()
+
+	// Job和Plan通过授权处理获取令牌，需要在它们之后关闭
+	if c.auh != nil {
+		setErr(c.auh.Shutdown(ctx))
+	}
+	if c.ownsMQTT && c.mqcli != nil {
+		c.mqcli.Disconnect()
+	}
+	if c.permission != nil {
+		setErr(c.permission.Close())
+	}
+	if c.ownsHTTP {
+		c.httpClient.CloseIdleConnections()
+	}
+	return firstErr
+}
+
+// Close 关闭SDK客户端，等待进行中的请求完成
+func (c *Client) Close() error {
+	return c.Shutdown(context.Background())
+}
+
+// HealthCheck 并发检查已配置的各服务是否可用，返回以服务名称为键的检查结果，值为nil表示可用。
+// 服务名称：as、mqtt、permission、job、plan、ats
+func (c *Client) HealthCheck(ctx context.Context) map[string]error {
+	checks := make(map[string]func(context.Context) error)
+	if c.auh != nil {
+		checks["as"] = c.auh.HealthCheck
+	}
+	if c.mqttAddr != "" {
+		addr := c.mqttAddr
+		checks["mqtt"] = func(ctx context.Context) error {
+			return utils.CheckTCP(ctx, addr)
+		}
+	}
+	if c.permission != nil {
+		checks["permission"] = c.permission.HealthCheck
+	}
+	if c.job != nil {
+		checks["job"] = c.job.HealthCheck
+	}
+	if c.plan != nil {
+		checks["plan"] = c.plan.HealthCheck
+	}
+	if c.ats != nil {
+		checks["ats"] = c.ats.HealthCheck
+	}
+
+	var (
+		wg     sync.WaitGroup
+		lock   sync.Mutex
+		result = make(map[string]error, len(checks))
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) error) {
+			defer wg.Done()
+			err := check(ctx)
+			lock.Lock()
+			result[name] = err
+			lock.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+	return result
+}
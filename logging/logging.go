@@ -0,0 +1,84 @@
+// Package logging 定义SDK的日志接口
+//
+// Logger 与 log/slog 兼容，可以直接使用 *slog.Logger：
+//
+//	cli.Authorize().SetLogger(slog.Default())
+//
+// 默认不输出任何日志。
+package logging
+
+import (
+	"sync"
+
+	"github.com/antlinker/sdk/redact"
+)
+
+// 日志字段名
+const (
+	KeyComponent = "component"
+	KeyRouter    = "router"
+	KeyMethod    = "method"
+	KeyStatus    = "status"
+	KeyDuration  = "duration"
+	KeyUID       = "uid"
+	KeyType      = "type"
+	KeyTopic     = "topic"
+	KeyMT        = "mt"
+	KeyError     = "error"
+	KeyResponse  = "response"
+)
+
+// Logger 日志接口，args为交替出现的字段名和字段值
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{}) {}
+func (nopLogger) Info(msg string, args ...interface{})  {}
+func (nopLogger) Warn(msg string, args ...interface{})  {}
+func (nopLogger) Error(msg string, args ...interface{}) {}
+
+// Nop 不输出任何日志的Logger
+func Nop() Logger {
+	return nopLogger{}
+}
+
+var (
+	lock       sync.RWMutex
+	defaultLog Logger = nopLogger{}
+)
+
+// SetDefault 设置未单独指定Logger的处理使用的日志，为nil时不输出日志
+func SetDefault(l Logger) {
+	if l == nil {
+		l = nopLogger{}
+	}
+	lock.Lock()
+	defaultLog = l
+	lock.Unlock()
+}
+
+// Default 获取默认的日志
+func Default() Logger {
+	lock.RLock()
+	defer lock.RUnlock()
+	return defaultLog
+}
+
+// Or 返回l，l为nil时返回默认的日志
+func Or(l Logger) Logger {
+	if l == nil {
+		return Default()
+	}
+	return l
+}
+
+// MaskUID 隐藏用户ID的中间部分，只保留前后各两个字符
+func MaskUID(uid string) string {
+	return redact.Partial(uid, 2)
+}
@@ -0,0 +1,69 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/logging"
+)
+
+var _ logging.Logger = (*slog.Logger)(nil)
+
+func TestMaskUID(t *testing.T) {
+	for uid, want := range map[string]string{
+		"":           "****",
+		"abc":        "****",
+		"user123456": "us****56",
+		"张三丰李四王五":    "张三****王五",
+	} {
+		if got := logging.MaskUID(uid); got != want {
+			t.Errorf("MaskUID(%q) = %q, want %q", uid, got, want)
+		}
+	}
+}
+
+func TestAuthorizeHandleLogger(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/token" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "t", "expires_in": 3600})
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":11}`))
+	}))
+	defer srv.Close()
+
+	buf := new(bytes.Buffer)
+	ah := asapi.NewAuthorizeHandle(&asapi.Config{ASURL: srv.URL, ClientID: "id", ClientSecret: "secret"})
+	defer ah.Close()
+	ah.SetLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	if _, result := ah.GetUser("user123456"); result == nil {
+		t.Fatal("expected error result")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected token and request logs, got %q", buf.String())
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["level"] != "WARN" || entry["router"] != "/api/authorize/getuser" || entry["status"] != float64(404) {
+		t.Fatalf("unexpected entry: %v", entry)
+	}
+	if entry["uid"] != "us****56" {
+		t.Fatalf("uid is not masked: %v", entry["uid"])
+	}
+	if strings.Contains(buf.String(), "user123456") {
+		t.Fatal("log contains raw uid")
+	}
+}
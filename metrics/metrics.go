@@ -0,0 +1,89 @@
+// Package metrics 定义SDK外部调用的指标记录接口
+//
+// 默认不记录任何指标，通过 SetRecorder 设置指标记录的实现，
+// Prometheus的实现见 metrics/prometheus。
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 组件名称
+const (
+	ComponentASAPI      = "asapi"
+	ComponentJob        = "job"
+	ComponentPlan       = "plan"
+	ComponentTodo       = "todo"
+	ComponentWelcome    = "welcome"
+	ComponentPermission = "permission"
+	ComponentATS        = "ats"
+)
+
+// Recorder 指标记录
+type Recorder interface {
+	// ObserveRequest 记录一次请求，operation为路由、任务类型、消息主题或RPC方法，
+	// code为错误码，请求成功时为空字符串
+	ObserveRequest(component, operation, code string, duration time.Duration)
+	// ObserveSize 记录请求或响应数据的大小(单位字节)
+	ObserveSize(component, operation string, size int)
+}
+
+type nopRecorder struct{}
+
+func (nopRecorder) ObserveRequest(component, operation, code string, duration time.Duration) {}
+func (nopRecorder) ObserveSize(component, operation string, size int)                        {}
+
+var (
+	lock     sync.RWMutex
+	recorder Recorder = nopRecorder{}
+)
+
+// SetRecorder 设置SDK使用的指标记录，为nil时不记录指标
+func SetRecorder(r Recorder) {
+	if r == nil {
+		r = nopRecorder{}
+	}
+	lock.Lock()
+	recorder = r
+	lock.Unlock()
+}
+
+// Default 获取SDK使用的指标记录
+func Default() Recorder {
+	lock.RLock()
+	defer lock.RUnlock()
+	return recorder
+}
+
+// ObserveRequest 使用SDK的指标记录记录一次请求
+func ObserveRequest(component, operation, code string, start time.Time) {
+	Default().ObserveRequest(component, operation, code, time.Since(start))
+}
+
+// ObserveSize 使用SDK的指标记录记录数据大小
+func ObserveSize(component, operation string, size int) {
+	Default().ObserveSize(component, operation, size)
+}
+
+// ErrorCode 将错误转换为指标中的错误码：nil为空字符串，带有HTTP状态码的错误为状态码，
+// 超时为timeout，取消为canceled，其他错误为error
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var se interface{ StatusCode() int }
+	if errors.As(err, &se) {
+		return strconv.Itoa(se.StatusCode())
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	}
+	return "error"
+}
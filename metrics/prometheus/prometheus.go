@@ -0,0 +1,76 @@
+// Package prometheus 使用Prometheus实现SDK的指标记录
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultNamespace 默认的指标命名空间
+const DefaultNamespace = "antsdk"
+
+// New 创建Prometheus指标记录并注册到reg，reg为nil时注册到prometheus.DefaultRegisterer，
+// namespace为空时使用DefaultNamespace
+func New(reg prometheus.Registerer, namespace string) (*Recorder, error) {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+
+	r := &Recorder{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "SDK外部调用的请求数量",
+		}, []string{"component", "operation"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "request_errors_total",
+			Help:      "SDK外部调用的错误数量",
+		}, []string{"component", "operation", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "SDK外部调用的耗时",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"component", "operation"}),
+		size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "payload_size_bytes",
+			Help:      "SDK外部调用的数据大小",
+			Buckets:   prometheus.ExponentialBuckets(1024, 4, 8),
+		}, []string{"component", "operation"}),
+	}
+
+	for _, c := range []prometheus.Collector{r.requests, r.errors, r.duration, r.size} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Recorder Prometheus指标记录
+type Recorder struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	size     *prometheus.HistogramVec
+}
+
+// ObserveRequest 记录一次请求
+func (r *Recorder) ObserveRequest(component, operation, code string, duration time.Duration) {
+	r.requests.WithLabelValues(component, operation).Inc()
+	if code != "" {
+		r.errors.WithLabelValues(component, operation, code).Inc()
+	}
+	r.duration.WithLabelValues(component, operation).Observe(duration.Seconds())
+}
+
+// ObserveSize 记录数据大小
+func (r *Recorder) ObserveSize(component, operation string, size int) {
+	r.size.WithLabelValues(component, operation).Observe(float64(size))
+}
@@ -0,0 +1,59 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/antlinker/sdk/metrics"
+	"github.com/antlinker/sdk/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecorder(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	r, err := New(reg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(reg, ""); err == nil {
+		t.Fatal("expected duplicate registration error")
+	}
+
+	metrics.SetRecorder(r)
+	defer metrics.SetRecorder(nil)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	_, err = utils.PostJSON(context.Background(), srv.URL, nil)
+	code := metrics.ErrorCode(err)
+	if code != "502" {
+		t.Fatalf("unexpected code: %q", code)
+	}
+	metrics.Default().ObserveRequest(metrics.ComponentJob, "test", code, 0)
+	metrics.Default().ObserveRequest(metrics.ComponentJob, "test", "", 0)
+	metrics.ObserveSize(metrics.ComponentATS, "pdf", 2048)
+
+	if v := testutil.ToFloat64(r.requests.WithLabelValues("job", "test")); v != 2 {
+		t.Fatalf("unexpected requests: %v", v)
+	}
+	if v := testutil.ToFloat64(r.errors.WithLabelValues("job", "test", "502")); v != 1 {
+		t.Fatalf("unexpected errors: %v", v)
+	}
+	if n := testutil.CollectAndCount(r.size); n != 1 {
+		t.Fatalf("unexpected size series: %d", n)
+	}
+
+	if code := metrics.ErrorCode(context.DeadlineExceeded); code != "timeout" {
+		t.Fatalf("unexpected code: %q", code)
+	}
+	if code := metrics.ErrorCode(errors.New("x")); code != "error" {
+		t.Fatalf("unexpected code: %q", code)
+	}
+}
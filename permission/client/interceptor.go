@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/antlinker/sdk/logging"
+	"github.com/antlinker/sdk/metrics"
+	"github.com/antlinker/sdk/redact"
+	"github.com/antlinker/sdk/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// unaryInterceptor 为RPC调用创建span、记录指标和日志，并将链路上下文写入gRPC元数据
+func (c *RPCClient) unaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) (err error) {
+	service, name := splitMethod(method)
+	ctx, span := tracing.Start(ctx, strings.TrimPrefix(method, "/"),
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", name),
+	)
+	start := time.Now()
+	defer func() {
+		code := status.Code(err)
+		span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+		tracing.End(span, err)
+
+		var mcode string
+		if err != nil {
+			mcode = code.String()
+		}
+		metrics.ObserveRequest(metrics.ComponentPermission, strings.TrimPrefix(method, "/"), mcode, start)
+
+		fields := []interface{}{
+			logging.KeyComponent, "permission",
+			logging.KeyMethod, method,
+			logging.KeyStatus, code.String(),
+			logging.KeyDuration, time.Since(start),
+		}
+		if err != nil {
+			logging.Or(c.log).Error("rpc call failed", append(fields, logging.KeyError, redact.String(err.Error()))...)
+		} else {
+			logging.Or(c.log).Debug("rpc call", fields...)
+		}
+	}()
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	for k, v := range tracing.Inject(ctx) {
+		md.Set(k, v)
+	}
+	return invoker(metadata.NewOutgoingContext(ctx, md), method, req, reply, cc, opts...)
+}
+
+// splitMethod 将/package.Service/Method格式的方法名拆分为服务名和方法名
+func splitMethod(method string) (service, name string) {
+	method = strings.TrimPrefix(method, "/")
+	if i := strings.LastIndex(method, "/"); i >= 0 {
+		return method[:i], method[i+1:]
+	}
+	return "", method
+}
@@ -3,9 +3,11 @@ package client
 import (
 	"fmt"
 
+	"github.com/antlinker/sdk/logging"
 	"github.com/antlinker/sdk/permission/proto/permission"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 )
 
 const (
@@ -25,6 +27,8 @@ type RPCConfig struct {
 }
 
 // InitRPCClient 初始化全局的RPC客户端
+//
+// Deprecated: 使用 NewRPCClient 或 sdk.Client 的 Permission 方法
 func InitRPCClient(cfg *RPCConfig) {
 	cli, err := NewRPCClient(cfg)
 	if err != nil {
@@ -43,26 +47,59 @@ func NewRPCClient(cfg *RPCConfig) (*RPCClient, error) {
 		cfg.Addr = fmt.Sprintf(":%d", DefaultRPCPort)
 	}
 
+	c := new(RPCClient)
+
 	var opts []grpc.DialOption
 	opts = append(opts, grpc.WithInsecure())
 	opts = append(opts, grpc.WithPerRPCCredentials(new(tokenCredential).Init(cfg.AppID, cfg.AppKey)))
+	opts = append(opts, grpc.WithUnaryInterceptor(c.unaryInterceptor))
 
 	conn, err := grpc.Dial(cfg.Addr, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &RPCClient{
-		PermissionClient: permission.NewPermissionClient(conn),
-	}, nil
+	c.PermissionClient = permission.NewPermissionClient(conn)
+	c.conn = conn
+	return c, nil
 }
 
 // RPCClient RPC客户端
 type RPCClient struct {
 	PermissionClient permission.PermissionClient
+	conn             *grpc.ClientConn
+	log              logging.Logger
+}
+
+// SetLogger 设置RPC调用使用的日志，为nil时使用logging.Default()
+func (c *RPCClient) SetLogger(l logging.Logger) {
+	c.log = l
+}
+
+// Close 关闭RPC连接，进行中的请求将被取消
+func (c *RPCClient) Close() error {
+	return c.conn.Close()
+}
+
+// HealthCheck 等待RPC连接就绪，ctx结束前未就绪时返回错误
+func (c *RPCClient) HealthCheck(ctx context.Context) error {
+	for {
+		state := c.conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return fmt.Errorf("RPC连接已关闭")
+		}
+		if !c.conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("RPC连接未就绪(%s)：%v", state, ctx.Err())
+		}
+	}
 }
 
 // PermissionClient 权限客户端
+//
+// Deprecated: 使用 NewRPCClient 或 sdk.Client 的 Permission 方法
 func PermissionClient() permission.PermissionClient {
 	if rpcClient == nil {
 		panic("未初始化RPC客户端")
@@ -11,17 +11,21 @@ var (
 )
 
 // SetAuthorizeHandle 设置授权处理
+//
+// Deprecated: 使用 NewHandle 或 sdk.Client 的 Plan 方法
 func SetAuthorizeHandle(auh *asapi.AuthorizeHandle) {
 	if gHandle == nil {
-		gHandle = new(Handle)
+		gHandle = NewHandle(nil, nil)
 	}
 	gHandle.auh = auh
 }
 
 // SetConfig 设置配置参数
+//
+// Deprecated: 使用 NewHandle 或 sdk.Client 的 Plan 方法
 func SetConfig(cfg *Config) {
 	if gHandle == nil {
-		gHandle = new(Handle)
+		gHandle = NewHandle(nil, nil)
 	}
 	gHandle.cfg = cfg
 }
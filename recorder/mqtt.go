@@ -0,0 +1,57 @@
+package recorder
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/antlinker/go-mqtt/client"
+)
+
+// MQTT 包装MQTT客户端：录制模式下记录发布的消息后经由cli发布；
+// 回放模式下只检查是否有相同主题的记录，不会发布消息，cli可以为nil
+func (r *Recorder) MQTT(cli client.MqttClienter) client.MqttClienter {
+	return &mqttClient{MqttClienter: cli, r: r}
+}
+
+type mqttClient struct {
+	client.MqttClienter
+	r *Recorder
+}
+
+func (c *mqttClient) Publish(topic string, qos client.QoS, retain bool, data interface{}) (*client.MqttPacket, error) {
+	i := &Interaction{
+		Kind:    KindMQTT,
+		Topic:   topic,
+		Payload: string(newBody(payload(data))),
+	}
+
+	if c.r.mode == ModeReplay {
+		if _, err := c.r.match(i.key()); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	c.r.add(i)
+	return c.MqttClienter.Publish(topic, qos, retain, data)
+}
+
+func (c *mqttClient) Disconnect() {
+	if c.MqttClienter != nil {
+		c.MqttClienter.Disconnect()
+	}
+}
+
+func payload(data interface{}) []byte {
+	switch v := data.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	}
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return []byte(fmt.Sprint(data))
+	}
+	return buf
+}
@@ -0,0 +1,284 @@
+// Package recorder 录制和回放SDK的HTTP请求及MQTT消息
+//
+// 录制模式下请求经由实际的RoundTripper发出，请求和响应（已隐藏敏感数据）保存到记录文件；
+// 回放模式下从记录文件中查找匹配的请求并返回记录的响应，不会访问网络。
+package recorder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/antlinker/sdk/redact"
+)
+
+// Mode 工作模式
+type Mode int
+
+const (
+	// ModeRecord 录制
+	ModeRecord Mode = iota
+	// ModeReplay 回放
+	ModeReplay
+)
+
+// 记录类型
+const (
+	KindHTTP = "http"
+	KindMQTT = "mqtt"
+)
+
+// ErrNoMatch 回放时没有匹配的记录
+type ErrNoMatch struct {
+	Key string
+}
+
+// Error 实现error接口
+func (e *ErrNoMatch) Error() string {
+	return fmt.Sprintf("recorder: 没有匹配的记录(%s)", e.Key)
+}
+
+// Request 记录的请求
+type Request struct {
+	Method string      `json:",omitempty"`
+	URL    string      `json:",omitempty"`
+	Header http.Header `json:",omitempty"`
+	Body   Body        `json:",omitempty"`
+}
+
+// Response 记录的响应
+type Response struct {
+	StatusCode int         `json:",omitempty"`
+	Header     http.Header `json:",omitempty"`
+	Body       Body        `json:",omitempty"`
+	Error      string      `json:",omitempty"`
+}
+
+// Body 记录的请求体或响应体，文本隐藏敏感数据后保存，二进制数据（如PDF）使用base64编码保存
+type Body string
+
+const base64Prefix = "base64:"
+
+func newBody(b []byte) Body {
+	if !utf8.Valid(b) {
+		return Body(base64Prefix + base64.StdEncoding.EncodeToString(b))
+	}
+	return Body(redact.String(string(b)))
+}
+
+// Bytes 解码后的数据
+func (b Body) Bytes() []byte {
+	if s := string(b); strings.HasPrefix(s, base64Prefix) {
+		if buf, err := base64.StdEncoding.DecodeString(s[len(base64Prefix):]); err == nil {
+			return buf
+		}
+	}
+	return []byte(b)
+}
+
+// Interaction 一次请求及其响应，或一条MQTT消息
+type Interaction struct {
+	Kind     string
+	Request  *Request  `json:",omitempty"`
+	Response *Response `json:",omitempty"`
+	Topic    string    `json:",omitempty"`
+	Payload  string    `json:",omitempty"`
+}
+
+// key 回放时用于匹配的键：HTTP请求为方法、路径、查询参数和请求体，MQTT消息为主题
+func (i *Interaction) key() string {
+	if i.Kind == KindMQTT {
+		return KindMQTT + " " + i.Topic
+	}
+	return i.Request.Method + " " + i.Request.URL + " " + string(i.Request.Body)
+}
+
+// Cassette 记录文件的内容
+type Cassette struct {
+	Interactions []*Interaction
+}
+
+// New 创建录制或回放，name为记录文件的路径，next为录制模式下实际发出请求的RoundTripper，
+// 为nil时使用http.DefaultTransport。回放模式下记录文件必须存在
+func New(name string, mode Mode, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{
+		name:     name,
+		mode:     mode,
+		next:     next,
+		cassette: new(Cassette),
+	}
+
+	if mode == ModeReplay {
+		buf, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(buf, r.cassette); err != nil {
+			return nil, fmt.Errorf("recorder: 读取记录文件%s发生错误：%v", name, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+// Recorder 录制和回放，实现了http.RoundTripper
+type Recorder struct {
+	name     string
+	mode     Mode
+	next     http.RoundTripper
+	lock     sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// Mode 工作模式
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client 使用Recorder的HTTP客户端
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip 实现http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		buf, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = buf
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	i := &Interaction{
+		Kind: KindHTTP,
+		Request: &Request{
+			Method: req.Method,
+			URL:    requestURI(req),
+			Header: redact.Default().Header(req.Header),
+			Body:   newBody(body),
+		},
+	}
+
+	if r.mode == ModeReplay {
+		m, err := r.match(i.key())
+		if err != nil {
+			return nil, err
+		}
+		return m.Response.toHTTP(req)
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		i.Response = &Response{Error: redact.String(err.Error())}
+		r.add(i)
+		return nil, err
+	}
+
+	buf, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(buf))
+
+	i.Response = &Response{
+		StatusCode: resp.StatusCode,
+		Header:     redact.Default().Header(resp.Header),
+		Body:       newBody(buf),
+	}
+	r.add(i)
+	return resp, nil
+}
+
+func (r *Recorder) add(i *Interaction) {
+	r.lock.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	r.lock.Unlock()
+}
+
+// match 按记录顺序查找第一个未使用的匹配记录
+func (r *Recorder) match(key string) (*Interaction, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for idx, i := range r.cassette.Interactions {
+		if !r.used[idx] && i.key() == key {
+			r.used[idx] = true
+			return i, nil
+		}
+	}
+	return nil, &ErrNoMatch{Key: key}
+}
+
+// Unused 回放模式下尚未使用的记录数量
+func (r *Recorder) Unused() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var n int
+	for _, used := range r.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+// Save 录制模式下将记录写入记录文件，回放模式下不做任何处理
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.lock.Lock()
+	buf, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.name, buf, 0644)
+}
+
+// Close 保存记录
+func (r *Recorder) Close() error {
+	return r.Save()
+}
+
+// requestURI 请求的路径和查询参数，不包含主机，以便记录可以在不同的环境中回放；
+// 查询参数中的敏感数据（如access_token）被隐藏，录制和回放使用相同的规则，不影响匹配
+func requestURI(req *http.Request) string {
+	return redact.String(req.URL.RequestURI())
+}
+
+func (resp *Response) toHTTP(req *http.Request) (*http.Response, error) {
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%s", resp.Error)
+	}
+	header := resp.Header
+	if header == nil {
+		header = make(http.Header)
+	}
+	body := resp.Body.Bytes()
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode)),
+		StatusCode:    resp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,139 @@
+package recorder_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/antlinker/go-mqtt/client"
+	"github.com/antlinker/sdk"
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/ats"
+	"github.com/antlinker/sdk/job"
+	"github.com/antlinker/sdk/recorder"
+	"github.com/antlinker/sdk/todo"
+)
+
+type fakeMQTT struct {
+	client.MqttClienter
+	topics []string
+}
+
+func (c *fakeMQTT) Publish(topic string, qos client.QoS, retain bool, data interface{}) (*client.MqttPacket, error) {
+	c.topics = append(c.topics, topic)
+	return nil, nil
+}
+
+func server() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth2/token":
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "secret-token", "expires_in": 3600})
+		case "/api/authorize/getuser":
+			json.NewEncoder(w).Encode(map[string]interface{}{"UserCode": "1001", "IDCard": "11010119900101001X"})
+		case "/oauth2/verify":
+			json.NewEncoder(w).Encode(map[string]interface{}{"user_id": "u1", "client_id": "c1", "expires_in": 3600})
+		case "/api/authorize/getantuser":
+			json.NewEncoder(w).Encode(map[string]interface{}{"ANTUID": []string{"a1"}})
+		case "/job/exec":
+			w.Write([]byte(`"ok"`))
+		case "/api/pdf":
+			zw := gzip.NewWriter(w)
+			zw.Write([]byte("%PDF-1.4 \xff\xfe"))
+			zw.Close()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func run(t *testing.T, rec *recorder.Recorder, addr string, mq client.MqttClienter) {
+	cli, err := sdk.New(&sdk.Options{
+		AS:         &asapi.Config{ASURL: addr, ClientID: "id", ClientSecret: "secret"},
+		Job:        &job.Config{HTTPAddr: addr},
+		ATS:        &ats.Config{HTTPAddr: addr},
+		Transport:  rec,
+		MQTTClient: rec.MQTT(mq),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	info, result := cli.Authorize().GetUser("u1")
+	if result != nil {
+		t.Fatal(result)
+	}
+	if info.UserCode != "1001" {
+		t.Fatalf("unexpected user: %+v", info)
+	}
+	if uid, _, result := cli.Authorize().VerifyToken("user-token"); result != nil || uid != "u1" {
+		t.Fatalf("unexpected verify result: %s, %v", uid, result)
+	}
+	if err := cli.Job().ModifyStaffClass("u1"); err != nil {
+		t.Fatal(err)
+	}
+	pdf, err := cli.ATS().ConvertHTMLToPDF([]byte("<html></html>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(pdf, []byte("%PDF-1.4 \xff\xfe")) {
+		t.Fatalf("unexpected pdf: %q", pdf)
+	}
+	if err := cli.Todo().Done(&todo.DoneRequest{UID: "u1", BuID: "b1", TodoType: "t"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "cassette.json")
+
+	srv := server()
+	rec, err := recorder.New(name, recorder.ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mq := new(fakeMQTT)
+	run(t, rec, srv.URL, mq)
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+	if len(mq.topics) != 1 || mq.topics[0] != "S/TODO" {
+		t.Fatalf("unexpected topics: %v", mq.topics)
+	}
+
+	buf, err := recorderFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"secret-token", "user-token", "11010119900101001X"} {
+		if strings.Contains(buf, v) {
+			t.Fatalf("cassette contains sensitive value %q", v)
+		}
+	}
+
+	rec, err = recorder.New(name, recorder.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run(t, rec, "http://127.0.0.1:1", nil)
+	if n := rec.Unused(); n != 0 {
+		t.Fatalf("expected all interactions to be replayed, %d unused", n)
+	}
+
+	if _, err := rec.Client().Get("http://127.0.0.1:1/unknown"); err == nil {
+		t.Fatal("expected no match error")
+	}
+}
+
+func recorderFile(name string) (string, error) {
+	buf, err := ioutil.ReadFile(name)
+	return string(buf), err
+}
@@ -0,0 +1,156 @@
+// Package redact 隐藏日志、错误信息和链路追踪中的敏感数据
+//
+// 默认隐藏密码、身份证号码、手机号码、令牌和客户端秘钥，字段名不区分大小写，
+// 可以通过 SetFields 或 AddFields 修改。
+package redact
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Mask 替换敏感数据的字符串
+const Mask = "****"
+
+// DefaultFields 默认隐藏的字段
+var DefaultFields = []string{
+	"Password",
+	"DefaultPassword",
+	"IDCard",
+	"MobilePhone",
+	"AccessToken",
+	"access_token",
+	"refresh_token",
+	"ClientSecret",
+	"client_secret",
+	"Authorization",
+}
+
+// New 创建隐藏指定字段的Redactor
+func New(fields ...string) *Redactor {
+	r := &Redactor{fields: make(map[string]bool, len(fields))}
+	for _, f := range fields {
+		r.fields[strings.ToLower(f)] = true
+	}
+	r.compile()
+	return r
+}
+
+// Redactor 敏感数据隐藏
+type Redactor struct {
+	fields map[string]bool
+	json   *regexp.Regexp // "Field": "value"
+	query  *regexp.Regexp // Field=value
+}
+
+func (r *Redactor) compile() {
+	if len(r.fields) == 0 {
+		return
+	}
+	names := make([]string, 0, len(r.fields))
+	for f := range r.fields {
+		names = append(names, regexp.QuoteMeta(f))
+	}
+	alt := strings.Join(names, "|")
+	r.json = regexp.MustCompile(`(?i)("(?:` + alt + `)"\s*:\s*)("(?:[^"\\]|\\.)*"|-?[0-9][0-9.eE+-]*)`)
+	r.query = regexp.MustCompile(`(?i)(^|[?&\s,;])((?:` + alt + `)=)([^&\s,;]*)`)
+}
+
+// IsSensitive 字段是否需要隐藏
+func (r *Redactor) IsSensitive(field string) bool {
+	return r.fields[strings.ToLower(field)]
+}
+
+// String 隐藏字符串中JSON格式（"Field":"value"）和表单格式（Field=value）的敏感字段值
+func (r *Redactor) String(s string) string {
+	if r.json == nil || s == "" {
+		return s
+	}
+	s = r.json.ReplaceAllString(s, `${1}"`+Mask+`"`)
+	return r.query.ReplaceAllString(s, `${1}${2}`+Mask)
+}
+
+// Value 字段需要隐藏时返回隐藏后的值，否则原样返回
+func (r *Redactor) Value(field string, v interface{}) interface{} {
+	if r.IsSensitive(field) {
+		return Mask
+	}
+	return v
+}
+
+// Map 返回隐藏敏感字段后的map副本，嵌套的map同样处理
+func (r *Redactor) Map(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if r.IsSensitive(k) {
+			out[k] = Mask
+			continue
+		}
+		if vm, ok := v.(map[string]interface{}); ok {
+			v = r.Map(vm)
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// Header 返回隐藏敏感请求头后的副本
+func (r *Redactor) Header(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for k, v := range h {
+		if r.IsSensitive(k) {
+			out[k] = []string{Mask}
+			continue
+		}
+		out[k] = append([]string(nil), v...)
+	}
+	return out
+}
+
+var (
+	lock       sync.RWMutex
+	defaultRed = New(DefaultFields...)
+)
+
+// Default 获取SDK使用的Redactor
+func Default() *Redactor {
+	lock.RLock()
+	defer lock.RUnlock()
+	return defaultRed
+}
+
+// SetFields 设置SDK需要隐藏的字段，替换默认的字段
+func SetFields(fields ...string) {
+	r := New(fields...)
+	lock.Lock()
+	defaultRed = r
+	lock.Unlock()
+}
+
+// AddFields 在SDK当前需要隐藏的字段基础上增加字段
+func AddFields(fields ...string) {
+	lock.Lock()
+	defer lock.Unlock()
+	all := make([]string, 0, len(defaultRed.fields)+len(fields))
+	for f := range defaultRed.fields {
+		all = append(all, f)
+	}
+	defaultRed = New(append(all, fields...)...)
+}
+
+// String 使用SDK的Redactor隐藏字符串中的敏感数据
+func String(s string) string {
+	return Default().String(s)
+}
+
+// Partial 保留字符串前后各keep个字符，中间使用Mask替换，长度不足时全部替换
+func Partial(s string, keep int) string {
+	if utf8.RuneCountInString(s) <= keep*2 {
+		return Mask
+	}
+	r := []rune(s)
+	return string(r[:keep]) + Mask + string(r[len(r)-keep:])
+}
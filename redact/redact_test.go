@@ -0,0 +1,56 @@
+package redact
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	r := New(DefaultFields...)
+	for in, want := range map[string]string{
+		`{"UID":"u1","Password":"123456","idcard":"11010119900101001X"}`: `{"UID":"u1","Password":"****","idcard":"****"}`,
+		`{"MobilePhone": 13800138000, "Name": "张三"}`:                     `{"MobilePhone": "****", "Name": "张三"}`,
+		`{"access_token":"a\"b","expires_in":3600}`:                      `{"access_token":"****","expires_in":3600}`,
+		`grant_type=password&password=secret&username=u1`:                `grant_type=password&password=****&username=u1`,
+		`invalid client: client_secret=abc, code 401`:                    `invalid client: client_secret=****, code 401`,
+		`nothing sensitive`: `nothing sensitive`,
+	} {
+		if got := r.String(in); got != want {
+			t.Errorf("String(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestMapAndHeader(t *testing.T) {
+	r := New("Password", "AccessToken")
+	m := r.Map(map[string]interface{}{
+		"UID":      "u1",
+		"password": "x",
+		"User":     map[string]interface{}{"Password": "y"},
+	})
+	if m["UID"] != "u1" || m["password"] != Mask || m["User"].(map[string]interface{})["Password"] != Mask {
+		t.Fatalf("unexpected map: %v", m)
+	}
+
+	h := r.Header(http.Header{"Accesstoken": {"t"}, "Content-Type": {"application/json"}})
+	if h.Get("AccessToken") != Mask || h.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected header: %v", h)
+	}
+}
+
+func TestSetFields(t *testing.T) {
+	defer SetFields(DefaultFields...)
+
+	SetFields("Secret")
+	if got := String(`{"Secret":"x","Password":"y"}`); got != `{"Secret":"****","Password":"y"}` {
+		t.Fatalf("unexpected: %s", got)
+	}
+	AddFields("Password")
+	if got := String(`{"Secret":"x","Password":"y"}`); got != `{"Secret":"****","Password":"****"}` {
+		t.Fatalf("unexpected: %s", got)
+	}
+	SetFields()
+	if got := String(`{"Password":"y"}`); got != `{"Password":"y"}` {
+		t.Fatalf("unexpected: %s", got)
+	}
+}
@@ -0,0 +1,240 @@
+package roster
+
+import (
+	"context"
+	"sync"
+
+	"github.com/antlinker/sdk/asapi"
+)
+
+// Op 变更操作
+type Op string
+
+// 变更操作
+const (
+	OpNone    Op = "none"    // 无变化
+	OpCreate  Op = "create"  // 新增用户
+	OpUpdate  Op = "update"  // 更新用户
+	OpDelete  Op = "delete"  // 删除用户
+	OpInvalid Op = "invalid" // 记录校验或查询失败
+)
+
+// FieldChange 字段变化
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Change 记录与授权服务中用户信息的比对结果
+type Change struct {
+	Record  *Record
+	Op      Op
+	Fields  []FieldChange
+	Current *asapi.LoginUserInfo       // 授权服务中的用户信息，用户不存在时为nil
+	Basic   *asapi.GetUserUpdateResult // 授权服务中的姓名和部门，花名册中没有这两列时为nil
+	Err     error                      // 校验、查询或执行失败的错误
+}
+
+// Diff 将花名册中的记录与授权服务中的用户信息进行比对，不会修改任何数据
+// concurrency 并发查询数量，小于等于0时默认为10
+func Diff(ctx context.Context, ah *asapi.AuthorizeHandle, records []*Record, concurrency int) []*Change {
+	changes := make([]*Change, len(records))
+	forEach(ctx, len(records), concurrency, func(i int) {
+		changes[i] = diff(ah, records[i])
+	})
+	for i, c := range changes {
+		if c == nil {
+			changes[i] = &Change{Record: records[i], Op: OpInvalid, Err: ctx.Err()}
+		}
+	}
+	return changes
+}
+
+func diff(ah *asapi.AuthorizeHandle, rec *Record) *Change {
+	c := &Change{Record: rec}
+	if err := Validate(rec); err != nil {
+		c.Op = OpInvalid
+		c.Err = err
+		return c
+	}
+
+	req := &rec.Request
+	info, result := ah.GetUser(req.UID)
+	if result != nil {
+		if !result.IsUserNotFound() {
+			c.Op = OpInvalid
+			c.Err = result
+		} else if rec.Action == ActionDelete {
+			c.Op = OpNone
+		} else {
+			c.Op = OpCreate
+		}
+		return c
+	}
+	c.Current = info
+
+	if rec.Action == ActionDelete {
+		c.Op = OpDelete
+		return c
+	}
+
+	c.compare(FieldMobilePhone, info.MobilePhone, req.MobilePhone)
+	c.compare(FieldUserCode, info.UserCode, req.UserCode)
+	c.compare(FieldIDCard, info.IDCard, req.IDCard)
+	c.compare(FieldUniversity, info.University, req.University)
+
+	if req.Name != "" || req.DeptID != "" {
+		basic, result := ah.GetUserUpdate(req.UID)
+		if result != nil {
+			c.Op = OpInvalid
+			c.Err = result
+			return c
+		}
+		c.Basic = basic
+		c.compare(FieldName, basic.RealName, req.Name)
+		c.compare(FieldDeptID, basic.DeptID, req.DeptID)
+	}
+
+	c.Op = OpNone
+	if len(c.Fields) > 0 {
+		c.Op = OpUpdate
+	}
+	return c
+}
+
+// compare 比较字段，花名册中为空的字段不做更新
+func (c *Change) compare(field, current, value string) {
+	if value == "" || current == value {
+		return
+	}
+	c.Fields = append(c.Fields, FieldChange{Field: field, Old: current, New: value})
+}
+
+func (c *Change) changed(fields ...string) bool {
+	for _, fc := range c.Fields {
+		for _, f := range fields {
+			if fc.Field == f {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Report 执行结果
+type Report struct {
+	Created   int
+	Updated   int
+	Deleted   int
+	Unchanged int
+	Failed    int
+	Changes   []*Change
+}
+
+// Apply 执行比对结果中的新增、更新和删除操作，执行失败的错误记录在Change.Err中
+// concurrency 并发请求数量，小于等于0时默认为10
+func Apply(ctx context.Context, ah *asapi.AuthorizeHandle, changes []*Change, concurrency int) *Report {
+	applied := make([]bool, len(changes))
+	forEach(ctx, len(changes), concurrency, func(i int) {
+		if c := changes[i]; c.Err == nil {
+			c.Err = apply(ah, c)
+		}
+		applied[i] = true
+	})
+
+	report := &Report{Changes: changes}
+	for i, c := range changes {
+		if !applied[i] && c.Err == nil {
+			c.Err = ctx.Err()
+		}
+		if c.Err != nil || c.Op == OpInvalid {
+			report.Failed++
+			continue
+		}
+		switch c.Op {
+		case OpCreate:
+			report.Created++
+		case OpUpdate:
+			report.Updated++
+		case OpDelete:
+			report.Deleted++
+		default:
+			report.Unchanged++
+		}
+	}
+	return report
+}
+
+func apply(ah *asapi.AuthorizeHandle, c *Change) error {
+	req := &c.Record.Request
+
+	var result *asapi.ErrorResult
+	switch c.Op {
+	case OpCreate:
+		result = ah.AddStaffUser(req)
+	case OpDelete:
+		result = ah.DelStaffUser(req.UID)
+	case OpUpdate:
+		if c.changed(FieldMobilePhone, FieldUserCode, FieldIDCard, FieldUniversity) {
+			edit := &asapi.AuthorizeEditUserRequest{
+				MobilePhone: pick(req.MobilePhone, c.Current.MobilePhone),
+				UserCode:    pick(req.UserCode, c.Current.UserCode),
+				IDCard:      pick(req.IDCard, c.Current.IDCard),
+				University:  pick(req.University, c.Current.University),
+			}
+			if result = ah.EditUser(req.UID, edit); result != nil {
+				break
+			}
+		}
+		if c.changed(FieldName, FieldDeptID) {
+			// 花名册中为空的字段保持原值，避免清空姓名或部门
+			result = ah.UpdateUserBasic(&asapi.UpdateUserBasicRequest{
+				UID:    req.UID,
+				Name:   pick(req.Name, c.Basic.RealName),
+				DeptID: pick(req.DeptID, c.Basic.DeptID),
+			})
+		}
+	}
+
+	if result != nil {
+		return result
+	}
+	return nil
+}
+
+func pick(v, def string) string {
+	if v != "" {
+		return v
+	}
+	return def
+}
+
+// forEach 以有限的并发数量执行fn，ctx结束后不再执行新的任务
+func forEach(ctx context.Context, n, concurrency int, fn func(i int)) {
+	if concurrency <= 0 {
+		concurrency = 10
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
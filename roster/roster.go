@@ -0,0 +1,163 @@
+package roster
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/antlinker/sdk/asapi"
+)
+
+// 花名册中可以映射的字段
+const (
+	FieldUID         = "UID"
+	FieldMobilePhone = "MobilePhone"
+	FieldUserCode    = "UserCode"
+	FieldIDCard      = "IDCard"
+	FieldPassword    = "Password"
+	FieldUniversity  = "University"
+	FieldName        = "Name"
+	FieldSex         = "Sex"
+	FieldDeptID      = "DeptID"
+	FieldAction      = "Action"
+)
+
+// Action 记录的处理方式
+type Action string
+
+// 记录的处理方式，Action列为空时按新增或更新处理
+const (
+	ActionUpsert Action = ""
+	ActionDelete Action = "delete"
+)
+
+// Mapping 字段与表格列名的映射，未映射的字段使用字段名作为列名
+type Mapping map[string]string
+
+// ParseMapping 解析 "UID=工号,Name=姓名" 格式的字段映射
+func ParseMapping(s string) (Mapping, error) {
+	m := make(Mapping)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("无效的字段映射：%s", item)
+		}
+		m[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return m, nil
+}
+
+func (m Mapping) column(field string) string {
+	if v, ok := m[field]; ok {
+		return v
+	}
+	return field
+}
+
+// Record 花名册中的一条记录
+type Record struct {
+	Line    int                       // 在表格中的行号（从1开始，包含表头）
+	Action  Action                    // 处理方式
+	Request asapi.AddStaffUserRequest // 用户信息
+}
+
+// ReadFile 根据文件扩展名读取CSV或XLSX格式的花名册
+func ReadFile(name string, m Mapping) ([]*Record, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xlsx":
+		return ReadXLSXFile(name, m)
+	case ".csv", ".txt":
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ReadCSV(f, m)
+	}
+	return nil, fmt.Errorf("不支持的文件格式：%s", name)
+}
+
+// ReadCSV 读取CSV格式的花名册，第一行为表头
+func ReadCSV(r io.Reader, m Mapping) ([]*Record, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	var (
+		rows  [][]string
+		lines []int
+	)
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		rows = append(rows, row)
+		lines = append(lines, line)
+	}
+	return readRows(rows, lines, m)
+}
+
+// readRows 解析表格数据，lines为每行数据在表格中的行号
+func readRows(rows [][]string, lines []int, m Mapping) ([]*Record, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := make(map[string]int)
+	for i, name := range rows[0] {
+		header[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	if _, ok := header[m.column(FieldUID)]; !ok {
+		return nil, fmt.Errorf("表头中缺少用户ID列：%s", m.column(FieldUID))
+	}
+
+	var records []*Record
+	for i := 1; i < len(rows); i++ {
+		row := rows[i]
+		get := func(field string) string {
+			idx, ok := header[m.column(field)]
+			if !ok || idx >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[idx])
+		}
+
+		if get(FieldUID) == "" && strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+
+		rec := &Record{
+			Line: lines[i],
+			Request: asapi.AddStaffUserRequest{
+				UID:         get(FieldUID),
+				MobilePhone: get(FieldMobilePhone),
+				UserCode:    get(FieldUserCode),
+				IDCard:      strings.ToUpper(get(FieldIDCard)),
+				Password:    get(FieldPassword),
+				University:  get(FieldUniversity),
+				Name:        get(FieldName),
+				Sex:         asapi.Sex(strings.ToUpper(get(FieldSex))),
+				DeptID:      get(FieldDeptID),
+			},
+		}
+		switch strings.ToLower(get(FieldAction)) {
+		case "", "add", "update", "upsert":
+		case "d", "del", "delete":
+			rec.Action = ActionDelete
+		default:
+			return nil, fmt.Errorf("第%d行：无效的处理方式 %s", rec.Line, get(FieldAction))
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
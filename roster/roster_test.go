@@ -0,0 +1,134 @@
+package roster
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/antlinker/sdk/asapi"
+)
+
+func TestReadCSV(t *testing.T) {
+	data := "工号,姓名,手机号,学校,学号,操作\n" +
+		"u1,张三,13800000000,11906,2017001,\n" +
+		"\n" +
+		"u2,李四,12345,11906,2017002,delete\n"
+	m, err := ParseMapping("UID=工号,Name=姓名,MobilePhone=手机号,University=学校,UserCode=学号,Action=操作")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := ReadCSV(strings.NewReader(data), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("records: %d", len(records))
+	}
+	if r := records[0]; r.Line != 2 || r.Request.Name != "张三" || r.Action != ActionUpsert {
+		t.Fatalf("unexpected record: %+v", r)
+	}
+	if r := records[1]; r.Line != 4 || r.Action != ActionDelete {
+		t.Fatalf("unexpected record: %+v", r)
+	}
+	if err := Validate(records[0]); err != nil {
+		t.Fatal(err)
+	}
+	records[1].Action = ActionUpsert
+	if err := Validate(records[1]); err == nil {
+		t.Fatal("expected invalid mobile phone")
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	files := map[string]string{
+		"xl/sharedStrings.xml": `<sst><si><t>UID</t></si><si><t>Name</t></si><si><r><t>张</t></r><r><t>三</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+			`<row r="2"><c r="A2" t="inlineStr"><is><t>u1</t></is></c><c r="C2" t="s"><v>2</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	for name, content := range files {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+
+	records, err := ReadXLSX(bytes.NewReader(buf.Bytes()), int64(buf.Len()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Request.UID != "u1" || records[0].Request.Name != "张三" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+}
+
+func TestDiffApply(t *testing.T) {
+	var (
+		calls []string
+		basic asapi.UpdateUserBasicRequest
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+	})
+	mux.HandleFunc("/api/authorize/getuser", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["UID"] != "u1" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":11,"message":"未知的用户"}`))
+			return
+		}
+		w.Write([]byte(`{"UserCode":"2017001","University":"11906","MobilePhone":"13800000000"}`))
+	})
+	mux.HandleFunc("/api/authorize/getuserupdate", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"RealName":"张三","DeptID":"D1"}`))
+	})
+	mux.HandleFunc("/api/authorize/updateuserbasic", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "updateuserbasic")
+		json.NewDecoder(r.Body).Decode(&basic)
+		w.Write([]byte("{}"))
+	})
+	for _, router := range []string{"edituser", "addstaffuser"} {
+		router := router
+		mux.HandleFunc("/api/authorize/"+router, func(w http.ResponseWriter, r *http.Request) {
+			calls = append(calls, router)
+			w.Write([]byte("{}"))
+		})
+	}
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ah := asapi.NewAuthorizeHandle(&asapi.Config{ASURL: srv.URL})
+	records := []*Record{
+		{Line: 2, Request: asapi.AddStaffUserRequest{UID: "u1", UserCode: "2017001", University: "11906", MobilePhone: "13900000000", DeptID: "D2"}},
+		{Line: 3, Request: asapi.AddStaffUserRequest{UID: "u2", UserCode: "2017002", University: "11906"}},
+	}
+
+	changes := Diff(context.Background(), ah, records, 1)
+	if changes[0].Op != OpUpdate || len(changes[0].Fields) != 2 || changes[0].Fields[0].Field != FieldMobilePhone {
+		t.Fatalf("unexpected change: %+v", changes[0])
+	}
+	if changes[1].Op != OpCreate {
+		t.Fatalf("unexpected change: %+v", changes[1])
+	}
+
+	report := Apply(context.Background(), ah, changes, 1)
+	if report.Created != 1 || report.Updated != 1 || report.Failed != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if len(calls) != 3 {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+	// 花名册中没有姓名时保留原值
+	if basic.Name != "张三" || basic.DeptID != "D2" {
+		t.Fatalf("unexpected basic request: %+v", basic)
+	}
+}
@@ -0,0 +1,38 @@
+package roster
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/antlinker/sdk/validation"
+)
+
+// ValidationError 记录校验错误
+type ValidationError struct {
+	Line   int      // 行号
+	UID    string   // 用户ID
+	Errors []string // 错误信息
+}
+
+// Error 实现error接口
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("第%d行(%s)：%s", e.Line, e.UID, strings.Join(e.Errors, "；"))
+}
+
+// Validate 校验记录的必填字段以及手机号、身份证号（校验码和出生日期）、性别的格式
+func Validate(rec *Record) error {
+	req := &rec.Request
+
+	var errs validation.Errors
+	if rec.Action == ActionDelete {
+		errs.Required("UID", "用户ID", req.UID)
+	} else if err := req.Validate(); err != nil && !errors.As(err, &errs) {
+		return err
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Line: rec.Line, UID: req.UID, Errors: errs.Messages()}
+}
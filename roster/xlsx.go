@@ -0,0 +1,197 @@
+package roster
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ReadXLSXFile 读取XLSX格式花名册的第一个工作表，第一行为表头
+func ReadXLSXFile(name string, m Mapping) ([]*Record, error) {
+	zr, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return readXLSX(&zr.Reader, m)
+}
+
+// ReadXLSX 从r中读取XLSX格式花名册的第一个工作表
+func ReadXLSX(r io.ReaderAt, size int64, m Mapping) ([]*Record, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return readXLSX(zr, m)
+}
+
+func readXLSX(zr *zip.Reader, m Mapping) ([]*Record, error) {
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheet, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			Items []xlsxText `xml:"si"`
+		}
+		if err := decodeZipXML(f, &sst); err != nil {
+			return nil, err
+		}
+		for _, item := range sst.Items {
+			shared = append(shared, item.String())
+		}
+	}
+
+	f, ok := files[sheet]
+	if !ok {
+		return nil, errors.New("xlsx: 未找到工作表 " + sheet)
+	}
+	var ws struct {
+		Rows []struct {
+			Ref   int `xml:"r,attr"`
+			Cells []struct {
+				Ref       string   `xml:"r,attr"`
+				Type      string   `xml:"t,attr"`
+				Value     string   `xml:"v"`
+				InlineStr xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decodeZipXML(f, &ws); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(ws.Rows))
+	lines := make([]int, 0, len(ws.Rows))
+	for i, row := range ws.Rows {
+		line := row.Ref
+		if line == 0 {
+			line = i + 1
+		}
+		lines = append(lines, line)
+
+		var values []string
+		for j, c := range row.Cells {
+			col := columnIndex(c.Ref)
+			if col < 0 {
+				col = j
+			}
+			for len(values) <= col {
+				values = append(values, "")
+			}
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(c.Value)
+				if err != nil || idx < 0 || idx >= len(shared) {
+					return nil, errors.New("xlsx: 无效的共享字符串索引 " + c.Value)
+				}
+				values[col] = shared[idx]
+			case "inlineStr":
+				values[col] = c.InlineStr.String()
+			default:
+				values[col] = c.Value
+			}
+		}
+		rows = append(rows, values)
+	}
+
+	return readRows(rows, lines, m)
+}
+
+// firstSheetPath 根据workbook.xml及其关系文件查找第一个工作表的路径
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const defaultSheet = "xl/worksheets/sheet1.xml"
+
+	wf, ok := files["xl/workbook.xml"]
+	if !ok {
+		return defaultSheet, nil
+	}
+	var wb struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeZipXML(wf, &wb); err != nil {
+		return "", err
+	}
+	rf, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok || len(wb.Sheets) == 0 {
+		return defaultSheet, nil
+	}
+	var rels struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeZipXML(rf, &rels); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Items {
+		if rel.ID != wb.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return defaultSheet, nil
+}
+
+// columnIndex 将单元格引用（如"AB12"）转换为从0开始的列序号
+func columnIndex(ref string) int {
+	col := 0
+	n := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		col = col*26 + int(c-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return -1
+	}
+	return col - 1
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// xlsxText 共享字符串或内联字符串，富文本时由多个片段组成
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var buf strings.Builder
+	buf.WriteString(t.T)
+	for _, r := range t.Runs {
+		buf.WriteString(r.T)
+	}
+	return buf.String()
+}
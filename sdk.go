@@ -0,0 +1,208 @@
+// Package sdk 提供统一的SDK客户端，由一个配置参数创建授权处理、MQTT客户端和HTTP客户端，
+// 并通过 Todo、Welcome、Job、Plan、ATS 和 Permission 方法获取各服务的处理
+package sdk
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	mqtt "github.com/antlinker/go-mqtt/client"
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/ats"
+	"github.com/antlinker/sdk/config"
+	"github.com/antlinker/sdk/job"
+	"github.com/antlinker/sdk/logging"
+	"github.com/antlinker/sdk/metrics"
+	permclient "github.com/antlinker/sdk/permission/client"
+	"github.com/antlinker/sdk/plan"
+	"github.com/antlinker/sdk/redact"
+	"github.com/antlinker/sdk/todo"
+	"github.com/antlinker/sdk/utils"
+	"github.com/antlinker/sdk/welcome"
+)
+
+// Options SDK客户端配置参数，为nil的部分表示未配置，对应的方法返回nil
+type Options struct {
+	AS         *asapi.Config         // 授权服务，Todo、Welcome、Job和Plan依赖该配置
+	MQTT       *utils.MQTTConfig     // MQTT，Todo和Welcome依赖该配置
+	MQTTClient mqtt.MqttClienter     // 已创建的MQTT客户端，指定后忽略MQTT配置
+	HTTPClient *http.Client          // Job、Plan和ATS使用的HTTP客户端，默认超时60秒
+	Transport  http.RoundTripper     // 授权服务和默认HTTP客户端使用的RoundTripper（如recorder.Recorder）
+	Job        *job.Config           // 作业任务
+	Plan       *plan.Config          // 计划任务
+	ATS        *ats.Config           // 工具化服务
+	Permission *permclient.RPCConfig // 权限中心
+	Metrics    metrics.Recorder      // 指标记录，不为nil时设置为SDK全局的指标记录
+	Logger     logging.Logger        // 各处理使用的日志，为nil时使用logging.Default()
+	Redact     []string              // 日志、错误信息和链路追踪中需要隐藏的字段，为nil时使用redact.DefaultFields
+}
+
+// OptionsFromConfig 将配置文件加载的配置参数转换为SDK客户端配置参数
+func OptionsFromConfig(cfg *config.Config) *Options {
+	opts := &Options{
+		AS:         cfg.ASAPI,
+		Job:        cfg.Job,
+		Plan:       cfg.Plan,
+		ATS:        cfg.ATS,
+		Permission: cfg.Permission,
+	}
+	if cfg.MQTT != nil {
+		opts.MQTT = cfg.MQTT.Client()
+	}
+	return opts
+}
+
+// New 创建SDK客户端
+func New(opts *Options) (*Client, error) {
+	if opts == nil {
+		opts = new(Options)
+	}
+	if opts.AS == nil {
+		if opts.Job != nil {
+			return nil, errors.New("sdk: 作业任务依赖授权服务配置(AS)")
+		}
+		if opts.Plan != nil {
+			return nil, errors.New("sdk: 计划任务依赖授权服务配置(AS)")
+		}
+	}
+
+	if opts.Metrics != nil {
+		metrics.SetRecorder(opts.Metrics)
+	}
+	if opts.Redact != nil {
+		redact.SetFields(opts.Redact...)
+	}
+
+	c := &Client{
+		httpClient: opts.HTTPClient,
+		mqcli:      opts.MQTTClient,
+	}
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{Timeout: time.Second * 60, Transport: opts.Transport}
+		c.ownsHTTP = true
+	}
+
+	if opts.AS != nil {
+		c.auh = asapi.NewAuthorizeHandle(opts.AS)
+		c.auh.SetLogger(opts.Logger)
+		if opts.Transport != nil {
+			c.auh.SetTransport(opts.Transport)
+		}
+	}
+
+	if c.mqcli == nil && opts.MQTT != nil {
+		mqcfg := *opts.MQTT
+		if mqcfg.Logger == nil {
+			mqcfg.Logger = opts.Logger
+		}
+		cli, err := utils.NewMQTTClient(&mqcfg)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		c.mqcli = cli
+		c.ownsMQTT = true
+	}
+	if opts.MQTT != nil {
+		c.mqttAddr = opts.MQTT.BrokerAddress
+	}
+
+	if c.auh != nil && c.mqcli != nil {
+		c.todo = todo.NewHandle(c.auh, c.mqcli)
+		c.todo.SetLogger(opts.Logger)
+		c.welcome = welcome.NewHandle(c.auh, c.mqcli)
+		c.welcome.SetLogger(opts.Logger)
+	}
+
+	if opts.Job != nil {
+		c.job = job.NewHandle(c.auh, opts.Job)
+		c.job.SetHTTPClient(c.httpClient)
+		c.job.SetLogger(opts.Logger)
+	}
+
+	if opts.Plan != nil {
+		c.plan = plan.NewHandle(c.auh, opts.Plan)
+		c.plan.SetHTTPClient(c.httpClient)
+		c.plan.SetLogger(opts.Logger)
+	}
+
+	if opts.ATS != nil {
+		c.ats = ats.NewHandle(opts.ATS)
+		c.ats.SetHTTPClient(c.httpClient)
+		c.ats.SetLogger(opts.Logger)
+	}
+
+	if opts.Permission != nil {
+		cli, err := permclient.NewRPCClient(opts.Permission)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		c.permission = cli
+		c.permission.SetLogger(opts.Logger)
+	}
+
+	return c, nil
+}
+
+// Client SDK客户端
+type Client struct {
+	auh        *asapi.AuthorizeHandle
+	mqcli      mqtt.MqttClienter
+	httpClient *http.Client
+	todo       *todo.Handle
+	welcome    *welcome.Handle
+	job        *job.Handle
+	plan       *plan.Handle
+	ats        *ats.Handle
+	permission *permclient.RPCClient
+	mqttAddr   string
+	ownsMQTT   bool // MQTT客户端由New创建，关闭时断开
+	ownsHTTP   bool // HTTP客户端由New创建，关闭时释放空闲连接
+}
+
+// Authorize 授权处理
+func (c *Client) Authorize() *asapi.AuthorizeHandle {
+	return c.auh
+}
+
+// MQTTClient MQTT客户端
+func (c *Client) MQTTClient() mqtt.MqttClienter {
+	return c.mqcli
+}
+
+// HTTPClient Job、Plan和ATS使用的HTTP客户端
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
+// Todo 待办事项处理，需要配置授权服务和MQTT
+func (c *Client) Todo() *todo.Handle {
+	return c.todo
+}
+
+// Welcome 迎新处理，需要配置授权服务和MQTT
+func (c *Client) Welcome() *welcome.Handle {
+	return c.welcome
+}
+
+// Job 作业任务处理
+func (c *Client) Job() *job.Handle {
+	return c.job
+}
+
+// Plan 计划任务处理
+func (c *Client) Plan() *plan.Handle {
+	return c.plan
+}
+
+// ATS 工具化服务处理
+func (c *Client) ATS() *ats.Handle {
+	return c.ats
+}
+
+// Permission 权限中心RPC客户端
+func (c *Client) Permission() *permclient.RPCClient {
+	return c.permission
+}
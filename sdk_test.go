@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/ats"
+	"github.com/antlinker/sdk/job"
+)
+
+func TestNew(t *testing.T) {
+	c, err := New(&Options{
+		AS:  &asapi.Config{ASURL: "http://127.0.0.1:8099", ClientID: "id", ClientSecret: "secret"},
+		Job: &job.Config{HTTPAddr: "http://127.0.0.1:8080"},
+		ATS: &ats.Config{HTTPAddr: "http://127.0.0.1:8081"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Authorize() == nil || c.Job() == nil || c.ATS() == nil {
+		t.Fatal("expected configured handles")
+	}
+	if c.Todo() != nil || c.Welcome() != nil || c.Plan() != nil || c.Permission() != nil {
+		t.Fatal("expected unconfigured handles to be nil")
+	}
+
+	if _, err := New(&Options{Job: &job.Config{HTTPAddr: "http://127.0.0.1:8080"}}); err == nil {
+		t.Fatal("expected error when job is configured without AS")
+	}
+}
+
+func TestHealthCheckAndShutdown(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	c, err := New(&Options{
+		AS:  &asapi.Config{ASURL: srv.URL, ClientID: "id", ClientSecret: "secret", IsEnabledCache: true},
+		Job: &job.Config{HTTPAddr: srv.URL},
+		ATS: &ats.Config{HTTPAddr: "http://127.0.0.1:1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	result := c.HealthCheck(ctx)
+	if len(result) != 3 {
+		t.Fatalf("expected 3 checks, got %v", result)
+	}
+	if result["as"] != nil || result["job"] != nil {
+		t.Fatalf("expected as and job to be healthy: %v", result)
+	}
+	if result["ats"] == nil {
+		t.Fatal("expected ats to be unhealthy")
+	}
+	if err := c.Start(ctx); err == nil {
+		t.Fatal("expected start to fail when ats is unavailable")
+	}
+
+	if err := c.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, result := c.Authorize().GetUser("u1"); result == nil {
+		t.Fatal("expected request after shutdown to fail")
+	}
+	if err := c.Job().ModifyStaffName("u1", "name"); err == nil {
+		t.Fatal("expected job request after shutdown to fail")
+	}
+}
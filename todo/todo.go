@@ -11,9 +11,11 @@ var (
 )
 
 // SetAuthorizeHandle 设置授权处理
+//
+// Deprecated: 使用 NewHandle 或 sdk.Client 的 Todo 方法
 func SetAuthorizeHandle(auh *asapi.AuthorizeHandle) {
 	if gHandle == nil {
-		gHandle = new(Handle)
+		gHandle = NewHandle(nil, nil)
 	}
 	gHandle.auh = auh
 }
@@ -31,9 +33,11 @@ type MQTTConfig struct {
 }
 
 // SetMQTTClient 设置MQTT客户端
+//
+// Deprecated: 使用 NewHandle 或 sdk.Client 的 Todo 方法
 func SetMQTTClient(mqcfg *MQTTConfig) {
 	if gHandle == nil {
-		gHandle = new(Handle)
+		gHandle = NewHandle(nil, nil)
 	}
 
 	cfg := &utils.MQTTConfig{
@@ -56,7 +60,12 @@ func SetMQTTClient(mqcfg *MQTTConfig) {
 }
 
 // SetMQTTClienter 设置MQTT客户端
+//
+// Deprecated: 使用 NewHandle 或 sdk.Client 的 Todo 方法
 func SetMQTTClienter(cli client.MqttClienter) {
+	if gHandle == nil {
+		gHandle = NewHandle(nil, nil)
+	}
 	gHandle.mqcli = cli
 }
 
@@ -0,0 +1,142 @@
+// Package tracing 为SDK的外部调用提供OpenTelemetry链路追踪
+//
+// 默认使用otel全局的TracerProvider，未设置时不产生任何span；
+// 链路上下文默认按W3C Trace Context和Baggage格式传播。
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/antlinker/sdk/redact"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName 链路追踪的instrumentation名称
+const InstrumentationName = "github.com/antlinker/sdk"
+
+// EnvelopeKey MQTT消息体中存放链路上下文的字段名
+const EnvelopeKey = "TraceContext"
+
+var (
+	lock       sync.RWMutex
+	provider   trace.TracerProvider
+	propagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	)
+)
+
+// SetTracerProvider 设置SDK使用的TracerProvider，为nil时使用otel全局的TracerProvider
+func SetTracerProvider(tp trace.TracerProvider) {
+	lock.Lock()
+	provider = tp
+	lock.Unlock()
+}
+
+// SetPropagator 设置链路上下文的传播格式，默认为W3C Trace Context和Baggage
+func SetPropagator(p propagation.TextMapPropagator) {
+	lock.Lock()
+	propagator = p
+	lock.Unlock()
+}
+
+// Propagator 获取链路上下文的传播格式
+func Propagator() propagation.TextMapPropagator {
+	lock.RLock()
+	defer lock.RUnlock()
+	return propagator
+}
+
+func tracer() trace.Tracer {
+	lock.RLock()
+	tp := provider
+	lock.RUnlock()
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(InstrumentationName)
+}
+
+// Start 创建一个客户端类型的span，ctx为nil时使用context.Background()
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// End 结束span，err不为nil时记录隐藏敏感数据后的错误
+func End(span trace.Span, err error) {
+	if err != nil {
+		msg := redact.String(err.Error())
+		span.RecordError(&redactedError{err: err, msg: msg})
+		span.SetStatus(codes.Error, msg)
+	}
+	span.End()
+}
+
+// redactedError 隐藏了敏感数据的错误
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// Inject 将ctx中的链路上下文写入键值对，没有链路上下文时返回空的键值对
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	Propagator().Inject(ctx, carrier)
+	return carrier
+}
+
+// Extract 从键值对中读取链路上下文
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	return Propagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
+
+// InjectEnvelope 将ctx中的链路上下文以EnvelopeKey字段写入MQTT消息体，没有链路上下文时不修改消息体
+func InjectEnvelope(ctx context.Context, msg map[string]interface{}) {
+	if carrier := Inject(ctx); len(carrier) > 0 {
+		msg[EnvelopeKey] = carrier
+	}
+}
+
+// InjectHTTP 将ctx中的链路上下文写入HTTP请求头
+func InjectHTTP(ctx context.Context, header http.Header) {
+	Propagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// StartHTTP 为HTTP请求创建span并将链路上下文写入请求头，name为空时使用"HTTP <method>"
+func StartHTTP(ctx context.Context, name, method, url string, header http.Header) (context.Context, trace.Span) {
+	if name == "" {
+		name = "HTTP " + method
+	}
+	ctx, span := Start(ctx, name,
+		attribute.String("http.request.method", method),
+		attribute.String("url.full", url),
+	)
+	InjectHTTP(ctx, header)
+	return ctx, span
+}
+
+// EndHTTP 记录HTTP响应状态码并结束span，状态码大于等于400时标记为错误
+func EndHTTP(span trace.Span, statusCode int, err error) {
+	if statusCode > 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+		if err == nil && statusCode >= 400 {
+			span.SetStatus(codes.Error, http.StatusText(statusCode))
+		}
+	}
+	End(span, err)
+}
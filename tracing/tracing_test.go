@@ -0,0 +1,89 @@
+package tracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/tracing"
+	"github.com/antlinker/sdk/utils"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func setup(t *testing.T) (*tracetest.InMemoryExporter, context.Context) {
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	tracing.SetTracerProvider(tp)
+	t.Cleanup(func() { tracing.SetTracerProvider(nil) })
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "parent")
+	t.Cleanup(func() { span.End() })
+	return exp, ctx
+}
+
+func TestPostJSON(t *testing.T) {
+	exp, ctx := setup(t)
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Write([]byte(`"ok"`))
+	}))
+	defer srv.Close()
+
+	if _, err := utils.PostJSON(ctx, srv.URL, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exp.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "HTTP POST" {
+		t.Fatalf("unexpected spans: %v", spans)
+	}
+	if !strings.Contains(traceparent, spans[0].SpanContext.SpanID().String()) {
+		t.Fatalf("traceparent %q does not carry span %s", traceparent, spans[0].SpanContext.SpanID())
+	}
+	if spans[0].Parent.TraceID() != spans[0].SpanContext.TraceID() || !spans[0].Parent.IsValid() {
+		t.Fatal("expected span to have the caller's span as parent")
+	}
+}
+
+func TestAuthorizeHandle(t *testing.T) {
+	exp, ctx := setup(t)
+
+	headers := make(map[string]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers[r.URL.Path] = r.Header.Get("traceparent")
+		switch r.URL.Path {
+		case "/oauth2/token":
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "t", "expires_in": 3600})
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{"UserCode": "1"})
+		}
+	}))
+	defer srv.Close()
+
+	ah := asapi.NewAuthorizeHandle(&asapi.Config{ASURL: srv.URL, ClientID: "id", ClientSecret: "secret"})
+	defer ah.Close()
+
+	if _, result := ah.WithContext(ctx).GetUser("u1"); result != nil {
+		t.Fatal(result)
+	}
+
+	names := make(map[string]bool)
+	for _, s := range exp.GetSpans() {
+		names[s.Name] = true
+	}
+	if !names["asapi /oauth2/token"] || !names["asapi /api/authorize/getuser"] {
+		t.Fatalf("unexpected spans: %v", names)
+	}
+	for path, v := range headers {
+		if v == "" {
+			t.Fatalf("missing traceparent on %s", path)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CheckHTTP 检查HTTP服务是否可用，能够建立连接且状态码小于500即认为可用，cli为nil时使用http.DefaultClient
+func CheckHTTP(ctx context.Context, cli *http.Client, addr string) error {
+	if addr == "" {
+		return fmt.Errorf("未指定服务地址")
+	}
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+
+	req, err := http.NewRequest(http.MethodGet, addr, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := cli.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("服务不可用，状态码：%d", resp.StatusCode)
+	}
+	return nil
+}
+
+// CheckTCP 检查TCP服务是否可用，addr可以是host:port或tcp://host:port格式
+func CheckTCP(ctx context.Context, addr string) error {
+	if strings.Contains(addr, "://") {
+		u, err := url.Parse(addr)
+		if err != nil {
+			return err
+		}
+		addr = u.Host
+	}
+	if addr == "" {
+		return fmt.Errorf("未指定服务地址")
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
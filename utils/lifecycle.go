@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClosed 处理已关闭
+var ErrClosed = errors.New("sdk: 已关闭")
+
+// InFlight 跟踪进行中的请求，关闭后拒绝新的请求并等待进行中的请求完成
+type InFlight struct {
+	lock   sync.Mutex
+	closed bool
+	n      int
+	idle   chan struct{}
+}
+
+// Acquire 开始一个请求，已关闭时返回ErrClosed
+func (f *InFlight) Acquire() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.closed {
+		return ErrClosed
+	}
+	f.n++
+	return nil
+}
+
+// Release 结束一个请求
+func (f *InFlight) Release() {
+	f.lock.Lock()
+	f.n--
+	if f.n == 0 && f.idle != nil {
+		close(f.idle)
+		f.idle = nil
+	}
+	f.lock.Unlock()
+}
+
+// Closed 是否已关闭
+func (f *InFlight) Closed() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.closed
+}
+
+// Shutdown 关闭并等待进行中的请求完成，ctx结束时返回ctx.Err()
+func (f *InFlight) Shutdown(ctx context.Context) error {
+	f.lock.Lock()
+	f.closed = true
+	if f.n == 0 {
+		f.lock.Unlock()
+		return nil
+	}
+	if f.idle == nil {
+		f.idle = make(chan struct{})
+	}
+	idle := f.idle
+	f.lock.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
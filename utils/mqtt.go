@@ -4,6 +4,8 @@ import (
 	"crypto/tls"
 
 	"github.com/antlinker/go-mqtt/client"
+	"github.com/antlinker/sdk/logging"
+	"github.com/antlinker/sdk/redact"
 )
 
 // MQTTConfig mqtt配置参数
@@ -16,6 +18,7 @@ type MQTTConfig struct {
 	Username          string
 	PasswordHandler   func() string
 	EnableTLS         bool
+	Logger            logging.Logger `json:"-" yaml:"-" toml:"-"` // 连接日志，为nil时使用logging.Default()
 }
 
 // NewMQTTClient 创建默认的MQTT客户端
@@ -39,10 +42,55 @@ func NewMQTTClient(cfg *MQTTConfig) (cli client.MqttClienter, err error) {
 		return
 	}
 
-	l := &client.DefaultPrintListener{}
+	l := &mqttLogListener{
+		log:  logging.Or(cfg.Logger),
+		args: []interface{}{"broker", cfg.BrokerAddress, "client_id", cfg.ClientID},
+	}
 	cli.AddConnListener(l)
 	cli.AddDisConnListener(l)
 
 	err = cli.Connect()
 	return
 }
+
+// mqttLogListener 输出MQTT连接、断开和重连日志
+type mqttLogListener struct {
+	log  logging.Logger
+	args []interface{}
+}
+
+func (l *mqttLogListener) with(args ...interface{}) []interface{} {
+	return append(append([]interface{}{}, l.args...), args...)
+}
+
+func (l *mqttLogListener) OnConnStart(event *client.MqttConnEvent) {
+	l.log.Debug("mqtt connecting", l.args...)
+}
+
+func (l *mqttLogListener) OnConnSuccess(event *client.MqttConnEvent) {
+	l.log.Info("mqtt connected", l.args...)
+}
+
+func (l *mqttLogListener) OnConnFailure(event *client.MqttConnEvent, returncode int, err error) {
+	args := l.with("return_code", returncode)
+	if err != nil {
+		args = append(args, logging.KeyError, redact.String(err.Error()))
+	}
+	l.log.Error("mqtt connect failed", args...)
+}
+
+func (l *mqttLogListener) OnLostConn(event *client.MqttEvent, err error) {
+	args := l.args
+	if err != nil {
+		args = l.with(logging.KeyError, redact.String(err.Error()))
+	}
+	l.log.Warn("mqtt connection lost", args...)
+}
+
+func (l *mqttLogListener) OnDisconning(event *client.MqttEvent) {
+	l.log.Debug("mqtt disconnecting", l.args...)
+}
+
+func (l *mqttLogListener) OnDisconned(event *client.MqttEvent) {
+	l.log.Info("mqtt disconnected", l.args...)
+}
@@ -7,13 +7,35 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+
+	"github.com/antlinker/sdk/tracing"
 )
 
+// StatusError 响应状态码错误
+type StatusError struct {
+	Code int
+}
+
+// Error 实现error接口
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("请求发生错误，状态码：%d", e.Code)
+}
+
+// StatusCode 响应状态码
+func (e *StatusError) StatusCode() int {
+	return e.Code
+}
+
 // OptionHandle 自定义处理请求
 type OptionHandle func(*http.Request) (*http.Request, error)
 
 // PostJSON 发起一个json格式的POST请求
 func PostJSON(ctx context.Context, url string, body interface{}, options ...OptionHandle) (data []byte, err error) {
+	return PostJSONWithClient(ctx, nil, url, body, options...)
+}
+
+// PostJSONWithClient 使用指定的HTTP客户端发起一个json格式的POST请求，cli为nil时每次请求创建新的客户端
+func PostJSONWithClient(ctx context.Context, cli *http.Client, url string, body interface{}, options ...OptionHandle) (data []byte, err error) {
 	buf := new(bytes.Buffer)
 	if body != nil {
 		err = json.NewEncoder(buf).Encode(body)
@@ -29,9 +51,12 @@ func PostJSON(ctx context.Context, url string, body interface{}, options ...Opti
 
 	if len(options) > 0 {
 		req, err = options[0](req)
+		if err != nil {
+			return
+		}
 	}
 
-	err = Request(ctx, req, func(res *http.Response, err error) error {
+	err = Do(ctx, cli, req, func(res *http.Response, err error) error {
 		if err != nil {
 			return err
 		}
@@ -45,7 +70,7 @@ func PostJSON(ctx context.Context, url string, body interface{}, options ...Opti
 		data = buf
 
 		if v := res.StatusCode; v != 200 {
-			err = fmt.Errorf("请求发生错误，状态码：%d", v)
+			return &StatusError{Code: v}
 		}
 
 		return nil
@@ -54,8 +79,36 @@ func PostJSON(ctx context.Context, url string, body interface{}, options ...Opti
 	return
 }
 
+// Do 使用指定的HTTP客户端处理请求，cli为nil时使用Request
+func Do(ctx context.Context, cli *http.Client, req *http.Request, f func(*http.Response, error) error) error {
+	if cli == nil {
+		return Request(ctx, req, f)
+	}
+
+	ctx, span := tracing.StartHTTP(ctx, "", req.Method, req.URL.String(), req.Header)
+	var status int
+	err := withStatus(&status, f)(cli.Do(req.WithContext(ctx)))
+	tracing.EndHTTP(span, status, err)
+	return err
+}
+
+// withStatus 记录响应的状态码
+func withStatus(status *int, f func(*http.Response, error) error) func(*http.Response, error) error {
+	return func(resp *http.Response, err error) error {
+		if resp != nil {
+			*status = resp.StatusCode
+		}
+		return f(resp, err)
+	}
+}
+
 // Request HTTP请求处理
-func Request(ctx context.Context, req *http.Request, f func(*http.Response, error) error) error {
+func Request(ctx context.Context, req *http.Request, f func(*http.Response, error) error) (err error) {
+	ctx, span := tracing.StartHTTP(ctx, "", req.Method, req.URL.String(), req.Header)
+	var status int
+	defer func() { tracing.EndHTTP(span, status, err) }()
+	f = withStatus(&status, f)
+
 	tr := &http.Transport{}
 	client := &http.Client{Transport: tr}
 	c := make(chan error, 1)
@@ -0,0 +1,166 @@
+package validation
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// commonPasswords 内置的常用弱密码（小写）
+var commonPasswords = []string{
+	"123456", "1234567", "12345678", "123456789", "1234567890", "111111", "000000", "888888",
+	"666666", "123123", "654321", "112233", "121212", "520520", "147258369", "11111111",
+	"password", "password1", "password123", "passw0rd", "p@ssw0rd", "p@ssword", "admin", "admin123",
+	"admin@123", "root", "root123", "qwerty", "qwerty123", "qwe123", "asdfgh", "zxcvbn",
+	"1qaz2wsx", "1q2w3e4r", "1q2w3e4r5t", "qazwsx", "abc123", "abc12345", "abcd1234", "a123456",
+	"a12345678", "aa123456", "aa123456.", "aa12345678", "abc@123", "iloveyou", "woaini", "woaini1314",
+	"5201314", "welcome", "welcome1", "letmein", "test123", "test@123", "changeme", "default",
+}
+
+// PasswordPolicy 密码策略，零值字段使用默认值
+type PasswordPolicy struct {
+	MinLength       int      // 最小长度，默认8
+	MinClasses      int      // 至少包含的字符类别（小写字母、大写字母、数字、符号）数量，默认3
+	CommonPasswords []string // 额外禁止使用的密码（不区分大小写）
+	NoBuiltinList   bool     // 不使用内置的常用弱密码列表
+}
+
+// PasswordUser 检查密码时使用的用户信息，密码不能与这些信息相同
+type PasswordUser struct {
+	UID         string
+	UserCode    string
+	IDCard      string // 同时检查身份证号的后6位和后8位
+	MobilePhone string
+}
+
+func (p *PasswordPolicy) minLength() int {
+	if p == nil || p.MinLength <= 0 {
+		return 8
+	}
+	return p.MinLength
+}
+
+func (p *PasswordPolicy) minClasses() int {
+	if p == nil || p.MinClasses <= 0 {
+		return 3
+	}
+	if p.MinClasses > 4 {
+		return 4
+	}
+	return p.MinClasses
+}
+
+// Check 按密码策略检查密码，p为nil时使用默认策略
+func (p *PasswordPolicy) Check(password string, user *PasswordUser) error {
+	if n := len([]rune(password)); n < p.minLength() {
+		return fmt.Errorf("密码长度不能少于%d位", p.minLength())
+	}
+	if n := passwordClasses(password); n < p.minClasses() {
+		return fmt.Errorf("密码至少需要包含小写字母、大写字母、数字、符号中的%d种", p.minClasses())
+	}
+
+	lower := strings.ToLower(password)
+	if p == nil || !p.NoBuiltinList {
+		for _, s := range commonPasswords {
+			if lower == s {
+				return errors.New("密码过于常见，请更换")
+			}
+		}
+	}
+	if p != nil {
+		for _, s := range p.CommonPasswords {
+			if lower == strings.ToLower(s) {
+				return errors.New("密码过于常见，请更换")
+			}
+		}
+	}
+
+	if user != nil {
+		for _, s := range user.values() {
+			if s != "" && lower == strings.ToLower(s) {
+				return errors.New("密码不能与用户ID、学号、手机号或身份证号相同")
+			}
+		}
+	}
+	return nil
+}
+
+func (u *PasswordUser) values() []string {
+	values := []string{u.UID, u.UserCode, u.MobilePhone, u.IDCard}
+	for _, n := range []int{6, 8} {
+		if len(u.IDCard) > n {
+			values = append(values, u.IDCard[len(u.IDCard)-n:])
+		}
+	}
+	return values
+}
+
+// passwordClasses 返回密码包含的字符类别数量
+func passwordClasses(password string) int {
+	var lower, upper, digit, other int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			other = 1
+		}
+	}
+	return lower + upper + digit + other
+}
+
+// 生成密码使用的字符，去掉了容易混淆的0O1lI
+var passwordCharsets = []string{
+	"abcdefghijkmnopqrstuvwxyz",
+	"ABCDEFGHJKLMNPQRSTUVWXYZ",
+	"23456789",
+	"!@#$%^&*-_=+?",
+}
+
+// Generate 使用crypto/rand生成符合密码策略的随机密码，长度为12和最小长度中的较大值，
+// 包含全部4种字符类别。p为nil时使用默认策略
+func (p *PasswordPolicy) Generate() (string, error) {
+	length := p.minLength()
+	if length < 12 {
+		length = 12
+	}
+
+	all := strings.Join(passwordCharsets, "")
+	buf := make([]byte, length)
+	for i := range buf {
+		charset := all
+		if i < len(passwordCharsets) {
+			charset = passwordCharsets[i]
+		}
+		c, err := randIndex(len(charset))
+		if err != nil {
+			return "", err
+		}
+		buf[i] = charset[c]
+	}
+
+	// 打乱顺序，避免固定位置的字符类别
+	for i := len(buf) - 1; i > 0; i-- {
+		j, err := randIndex(i + 1)
+		if err != nil {
+			return "", err
+		}
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return string(buf), nil
+}
+
+func randIndex(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
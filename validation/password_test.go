@@ -0,0 +1,63 @@
+package validation
+
+import (
+	"testing"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	user := &PasswordUser{UID: "u1", UserCode: "Zs2020abc", IDCard: "11010519491231002X", MobilePhone: "13800000000"}
+
+	var p *PasswordPolicy
+	for pwd, ok := range map[string]bool{
+		"Xyz12345":  true,
+		"abc!2345":  true,
+		"Abc123":    false, // 长度不足
+		"abcdefgh1": false, // 字符类别不足
+		"P@ssw0rd":  false, // 常用密码
+		"zs2020ABC": false, // 与学号相同
+	} {
+		if err := p.Check(pwd, user); (err == nil) != ok {
+			t.Errorf("%s: %v", pwd, err)
+		}
+	}
+
+	p = &PasswordPolicy{MinLength: 6, MinClasses: 1, CommonPasswords: []string{"Antlinker"}}
+	for pwd, ok := range map[string]bool{
+		"qwerty99":  true,
+		"antlinker": false,
+		"qwerty":    false, // 内置列表
+		"31002x":    false, // 身份证号后6位
+		"1231002X":  false, // 身份证号后8位
+	} {
+		if err := p.Check(pwd, user); (err == nil) != ok {
+			t.Errorf("%s: %v", pwd, err)
+		}
+	}
+
+	p.NoBuiltinList = true
+	if err := p.Check("qwerty", user); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGeneratePassword(t *testing.T) {
+	seen := make(map[string]bool)
+	p := &PasswordPolicy{MinLength: 16, MinClasses: 4}
+	for i := 0; i < 100; i++ {
+		pwd, err := p.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pwd) != 16 || seen[pwd] {
+			t.Fatalf("unexpected password: %s", pwd)
+		}
+		if err := p.Check(pwd, nil); err != nil {
+			t.Fatalf("%s: %v", pwd, err)
+		}
+		seen[pwd] = true
+	}
+
+	if pwd, err := (*PasswordPolicy)(nil).Generate(); err != nil || len(pwd) != 12 {
+		t.Fatalf("unexpected password: %s, %v", pwd, err)
+	}
+}
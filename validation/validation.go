@@ -0,0 +1,121 @@
+// Package validation 校验用户信息，如手机号、身份证号（校验码和出生日期）、性别和密码强度，
+// 可以在调用授权服务前使用，也可以用于校验自己的表单
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var mobilePhoneRegexp = regexp.MustCompile(`^1[3-9]\d{9}$`)
+
+// MobilePhone 校验中国大陆手机号，为空时返回nil
+// 手机号、身份证号属于个人信息，错误信息中不包含校验的值
+func MobilePhone(s string) error {
+	if s == "" || mobilePhoneRegexp.MatchString(s) {
+		return nil
+	}
+	return errors.New("无效的手机号")
+}
+
+var (
+	idCardRegexp  = regexp.MustCompile(`^[1-9]\d{16}[\dXx]$`)
+	idCardWeights = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	idCardCodes   = "10X98765432"
+)
+
+// IDCard 校验18位居民身份证号的格式、出生日期和校验码，为空时返回nil
+func IDCard(s string) error {
+	if s == "" {
+		return nil
+	}
+	if !idCardRegexp.MatchString(s) {
+		return errors.New("无效的身份证号")
+	}
+	if _, err := IDCardBirthDate(s); err != nil {
+		return err
+	}
+
+	var sum int
+	for i, w := range idCardWeights {
+		sum += int(s[i]-'0') * w
+	}
+	if idCardCodes[sum%11] != strings.ToUpper(s[17:])[0] {
+		return errors.New("无效的身份证号（校验码错误）")
+	}
+	return nil
+}
+
+// IDCardBirthDate 返回18位居民身份证号中的出生日期
+func IDCardBirthDate(s string) (time.Time, error) {
+	if !idCardRegexp.MatchString(s) {
+		return time.Time{}, errors.New("无效的身份证号")
+	}
+	t, err := time.ParseInLocation("20060102", s[6:14], time.Local)
+	if err != nil || t.Year() < 1900 || t.After(time.Now()) {
+		return time.Time{}, errors.New("无效的身份证号（出生日期错误）")
+	}
+	return t, nil
+}
+
+// Sex 校验性别（F女,M男），为空时返回nil
+func Sex(s string) error {
+	switch s {
+	case "", "F", "M":
+		return nil
+	}
+	return fmt.Errorf("无效的性别：%s", s)
+}
+
+// FieldError 字段的校验错误
+type FieldError struct {
+	Field   string // 字段名，如 MobilePhone
+	Message string // 错误信息
+}
+
+// Error 实现error接口
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// Errors 多个字段的校验错误
+type Errors []*FieldError
+
+// Error 实现error接口
+func (e Errors) Error() string {
+	return strings.Join(e.Messages(), "；")
+}
+
+// Messages 返回所有错误信息
+func (e Errors) Messages() []string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Message
+	}
+	return msgs
+}
+
+// Err 没有错误时返回nil，否则返回e
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Required 字段为空时记录错误，name为错误信息中的字段名称
+func (e *Errors) Required(field, name, value string) {
+	if strings.TrimSpace(value) == "" {
+		*e = append(*e, &FieldError{Field: field, Message: name + "不能为空"})
+	}
+}
+
+// Check err不为nil时记录字段的错误
+func (e *Errors) Check(field string, err error) {
+	if err != nil {
+		*e = append(*e, &FieldError{Field: field, Message: err.Error()})
+	}
+}
@@ -0,0 +1,61 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIDCard(t *testing.T) {
+	for _, s := range []string{"", "11010519491231002X", "11010519491231002x", "440524199001010018", "110105200002290013"} {
+		if err := IDCard(s); err != nil {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+	for _, s := range []string{
+		"110105194912310021", // 校验码错误
+		"110105194913310020", // 出生日期错误
+		"110105299912310020", // 出生日期晚于当前时间
+		"11010519491231002",  // 长度错误
+		"01010519491231002X",
+	} {
+		if err := IDCard(s); err == nil {
+			t.Errorf("%s: expected error", s)
+		} else if strings.Contains(err.Error(), s) {
+			t.Errorf("%s: error contains the ID card: %v", s, err)
+		}
+	}
+
+	d, err := IDCardBirthDate("11010519491231002X")
+	if err != nil || d.Year() != 1949 || d.Month() != 12 || d.Day() != 31 {
+		t.Fatalf("unexpected birth date: %v, %v", d, err)
+	}
+}
+
+func TestMobilePhone(t *testing.T) {
+	for s, ok := range map[string]bool{"": true, "13800000000": true, "19912345678": true, "12345678901": false, "1380000000": false} {
+		err := MobilePhone(s)
+		if (err == nil) != ok || (err != nil && strings.Contains(err.Error(), s)) {
+			t.Errorf("%s: %v", s, err)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	var errs Errors
+	if errs.Err() != nil {
+		t.Fatal("expected nil error")
+	}
+	errs.Required("UID", "用户ID", " ")
+	errs.Check("Sex", Sex("X"))
+	errs.Check("MobilePhone", MobilePhone("13800000000"))
+
+	err := errs.Err()
+	var verrs Errors
+	if !errors.As(err, &verrs) || len(verrs) != 2 || verrs[1].Field != "Sex" {
+		t.Fatalf("unexpected errors: %v", err)
+	}
+	if err.Error() != "用户ID不能为空；无效的性别：X" {
+		t.Fatalf("unexpected message: %s", err)
+	}
+}
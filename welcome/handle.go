@@ -1,19 +1,28 @@
 package welcome
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/antlinker/go-mqtt/client"
 	"github.com/antlinker/sdk/asapi"
+	"github.com/antlinker/sdk/logging"
+	"github.com/antlinker/sdk/metrics"
+	"github.com/antlinker/sdk/redact"
+	"github.com/antlinker/sdk/tracing"
+	"github.com/antlinker/sdk/utils"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // NewHandle 创建迎新处理
 func NewHandle(auh *asapi.AuthorizeHandle, mqcli client.MqttClienter) *Handle {
 	return &Handle{
-		auh:   auh,
-		mqcli: mqcli,
+		auh:      auh,
+		mqcli:    mqcli,
+		inflight: new(utils.InFlight),
 	}
 }
 
@@ -21,6 +30,40 @@ func NewHandle(auh *asapi.AuthorizeHandle, mqcli client.MqttClienter) *Handle {
 type Handle struct {
 	auh   *asapi.AuthorizeHandle
 	mqcli client.MqttClienter
+
+	inflight *utils.InFlight
+	ctx      context.Context // 由WithContext设置，用于链路追踪
+	log      logging.Logger
+}
+
+// Shutdown 停止发布新的消息并等待正在发布的消息完成，ctx结束时返回ctx.Err()。
+// 不会断开MQTT客户端，MQTT客户端由创建者负责断开
+func (h *Handle) Shutdown(ctx context.Context) error {
+	return h.inflight.Shutdown(ctx)
+}
+
+// Close 关闭迎新处理，等待正在发布的消息完成
+func (h *Handle) Close() error {
+	return h.Shutdown(context.Background())
+}
+
+// WithContext 返回使用ctx的迎新处理副本，副本发起的请求以ctx中的span为父span，
+// 并与原处理共享配置和客户端
+func (h *Handle) WithContext(ctx context.Context) *Handle {
+	nh := new(Handle)
+	*nh = *h
+	nh.ctx = ctx
+	if h.auh != nil {
+		nh.auh = h.auh.WithContext(ctx)
+	}
+	return nh
+}
+
+func (h *Handle) context() context.Context {
+	if h.ctx != nil {
+		return h.ctx
+	}
+	return context.Background()
 }
 
 // ClickRequest 任务点击记录请求参数
@@ -109,7 +152,25 @@ func (h *Handle) Cancel(req *CancelRequest) (err error) {
 	return
 }
 
-func (h *Handle) publish(data interface{}) (err error) {
+func (h *Handle) publish(data map[string]interface{}) (err error) {
+	if err = h.inflight.Acquire(); err != nil {
+		return
+	}
+	defer h.inflight.Release()
+
+	ctx, span := tracing.Start(h.context(), "mqtt publish S/WELCOME",
+		attribute.String("messaging.system", "mqtt"),
+		attribute.String("messaging.destination.name", "S/WELCOME"),
+		attribute.String("messaging.operation.type", "publish"),
+	)
+	start := time.Now()
+	defer func() {
+		tracing.End(span, err)
+		metrics.ObserveRequest(metrics.ComponentWelcome, fmt.Sprintf("S/WELCOME:%v", data["MT"]), metrics.ErrorCode(err), start)
+		h.logPublish("S/WELCOME", data, start, err)
+	}()
+	tracing.InjectEnvelope(ctx, data)
+
 	buf, err := json.Marshal(data)
 	if err != nil {
 		return
@@ -118,3 +179,28 @@ func (h *Handle) publish(data interface{}) (err error) {
 	_, err = h.mqcli.Publish("S/WELCOME", client.QoS1, false, buf)
 	return
 }
+
+// SetLogger 设置迎新处理使用的日志，为nil时使用logging.Default()
+func (h *Handle) SetLogger(l logging.Logger) {
+	h.log = l
+}
+
+// logPublish 发布成功时输出Debug日志，失败时输出Error日志
+func (h *Handle) logPublish(topic string, data map[string]interface{}, start time.Time, err error) {
+	fields := []interface{}{
+		logging.KeyComponent, "welcome",
+		logging.KeyTopic, topic,
+		logging.KeyMT, data["MT"],
+		logging.KeyDuration, time.Since(start),
+	}
+	if uid, ok := data["UID"].(string); ok && uid != "" {
+		fields = append(fields, logging.KeyUID, logging.MaskUID(uid))
+	}
+
+	l := logging.Or(h.log)
+	if err != nil {
+		l.Error("mqtt publish failed", append(fields, logging.KeyError, redact.String(err.Error()))...)
+		return
+	}
+	l.Debug("mqtt publish", fields...)
+}
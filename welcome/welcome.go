@@ -11,17 +11,21 @@ var (
 )
 
 // SetAuthorizeHandle 设置授权处理
+//
+// Deprecated: 使用 NewHandle 或 sdk.Client 的 Welcome 方法
 func SetAuthorizeHandle(auh *asapi.AuthorizeHandle) {
 	if gHandle == nil {
-		gHandle = new(Handle)
+		gHandle = NewHandle(nil, nil)
 	}
 	gHandle.auh = auh
 }
 
 // SetMQTTClient 设置MQTT客户端
+//
+// Deprecated: 使用 NewHandle 或 sdk.Client 的 Welcome 方法
 func SetMQTTClient(mqcfg *utils.MQTTConfig) {
 	if gHandle == nil {
-		gHandle = new(Handle)
+		gHandle = NewHandle(nil, nil)
 	}
 
 	cfg := &utils.MQTTConfig{
@@ -44,7 +48,12 @@ func SetMQTTClient(mqcfg *utils.MQTTConfig) {
 }
 
 // SetMQTTClienter 设置MQTT客户端
+//
+// Deprecated: 使用 NewHandle 或 sdk.Client 的 Welcome 方法
 func SetMQTTClienter(cli client.MqttClienter) {
+	if gHandle == nil {
+		gHandle = NewHandle(nil, nil)
+	}
 	gHandle.mqcli = cli
 }
 